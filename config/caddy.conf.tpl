@@ -1,16 +1,86 @@
-{{ .domain }} {
+{{ .domain }}{{ if .wildcard }}, *.{{ .domain }}{{ end }}{{ range .aliases }}, {{ . }}{{ end }} {
         {{if eq .basicauth "Restricted"}}
+        {{- if .bypass }}
+        @auth not remote_ip{{ range .bypass }} {{ . }}{{ end }}
+        basicauth @auth {
+        {{- else }}
         basicauth /* {
-                demo $2a$12$Y.lglYtJKk89gqdK0pWiPurj5pzsmUccJgHlOMLcLJ5IMN4DZcrHG # demo demo
+        {{- end }}
+                {{ .authuser }} {{ .authhash }}
         }
         {{end}}
-        reverse_proxy {
-                to {{ .scheme }}://{{ .ip }}:{{ .port }}
-                {{if eq .scheme "https"}}
-                transport http {
-                  tls
-                  tls_insecure_skip_verify
+        {{- if .accesslog }}
+        log {
+                output file {{ .accesslog }} {
+                        roll_size {{ .logsize }}MiB
+                        roll_keep {{ .logkeep }}
                 }
-                {{end}}
         }
-}
\ No newline at end of file
+        {{- end }}
+        {{- if .caddybody }}
+        request_body {
+                max_size {{ .caddybody }}
+        }
+        {{- end }}
+        {{- range .resheader }}
+        header {{ .Name }} "{{ .Value }}"
+        {{- end }}
+        {{- if .allow }}
+        @denied not remote_ip{{ range .allow }} {{ . }}{{ end }}
+        handle @denied {
+                respond 403
+        }
+        {{- end }}
+        {{- if .paused }}
+        handle {
+                header Content-Type text/html
+                respond "<html><head><title>{{ .domain }} is paused</title></head><body><h1>This environment is paused</h1><p>Contact @{{ .owner }} to get access.</p></body></html>" 503
+        }
+        {{- else }}
+        {{- range $i, $r := .routes }}
+        @route{{ $i }} path {{ $r.Path }} {{ $r.Path }}/*
+        handle @route{{ $i }} {
+                reverse_proxy {
+                        to {{ $.scheme }}://{{ $r.IP }}:{{ $r.Port }}
+                        {{- template "proxy" $ }}
+                }
+        }
+        {{- end }}
+        handle {
+                reverse_proxy {
+                        to {{ .scheme }}://{{ .ip }}:{{ .port }}
+                        {{- template "proxy" . }}
+                }
+        }
+        {{- end }}
+}
+{{- define "proxy" }}
+                        {{- if .host }}
+                        header_up Host "{{ .host }}"
+                        {{- else if .wildcard }}
+                        header_up Host {host}
+                        {{- end }}
+                        {{- range .reqheader }}
+                        header_up {{ .Name }} "{{ .Value }}"
+                        {{- end }}
+                        {{- if or (eq .scheme "https") .timeouts.Custom .protocol }}
+                        transport http {
+                          {{- if eq .scheme "https" }}
+                          tls
+                          tls_insecure_skip_verify
+                          {{- end }}
+                          {{- if .protocol }}
+                          {{- if eq .scheme "https" }}
+                          versions 2
+                          {{- else }}
+                          versions h2c 2
+                          {{- end }}
+                          {{- end }}
+                          {{- if .timeouts.Custom }}
+                          dial_timeout {{ .timeouts.Connect }}s
+                          write_timeout {{ .timeouts.Send }}s
+                          read_timeout {{ .timeouts.Read }}s
+                          {{- end }}
+                        }
+                        {{- end }}
+{{- end }}
\ No newline at end of file
@@ -1,24 +1,85 @@
+{{- if not .paused -}}
 upstream {{ .domain }}-upstream {
     server {{ .ip }}:{{ .port }};
 }
 
+{{ end -}}
 server {
     listen 443 ssl http2;
     listen 80;
-    server_name {{ .domain }};
+    server_name {{ .domain }}{{ if .wildcard }} *.{{ .domain }}{{ end }}{{ range .aliases }} {{ . }}{{ end }};
+    {{- if .accesslog }}
+    access_log {{ .accesslog }};
+    error_log  {{ .errorlog }} warn;
+    {{- else }}
     access_log off;
     error_log  /dev/null;
+    {{- end }}
+    {{- if .maxbody }}
+    client_max_body_size {{ .maxbody }};
+    {{- end }}
 
     auth_basic {{ .basicauth }};
-    auth_basic_user_file /etc/nginx/passwd/default;
+    {{- if .passwdfile }}
+    auth_basic_user_file {{ .passwdfile }};
+    {{- end }}
+    {{- if .bypass }}
+
+    satisfy any;
+    {{- range .bypass }}
+    allow {{ . }};
+    {{- end }}
+    deny all;
+    {{- else if .allow }}
+    {{- range .allow }}
+    allow {{ . }};
+    {{- end }}
+    deny all;
+    {{- end }}
+
+    {{- range .resheader }}
+    add_header {{ .Name }} "{{ .Value }}" always;
+    {{- end }}
+    {{- if .paused }}
+
+    location / {
+        default_type text/html;
+        return 503 '<html><head><title>{{ .domain }} is paused</title></head><body><h1>This environment is paused</h1><p>Contact @{{ .owner }} to get access.</p></body></html>';
+    }
+    {{- else }}
+    {{- range .routes }}
+
+    location = {{ .Path }} {
+        {{ $.directive }}_pass {{ $.passproto }}://{{ .IP }}:{{ .Port }};
+        {{- template "proxy" $ }}
+    }
+
+    location {{ .Path }}/ {
+        {{ $.directive }}_pass {{ $.passproto }}://{{ .IP }}:{{ .Port }};
+        {{- template "proxy" $ }}
+    }
+    {{- end }}
 
     location / {
-        proxy_pass {{ .scheme }}://{{ .domain }}-upstream;
-        proxy_set_header Host $host;
-        proxy_set_header X-Real-IP $remote_addr;
-        proxy_set_header X-Forwarded-For $remote_addr;
-        proxy_connect_timeout 120;
-        proxy_send_timeout 120;
-        proxy_read_timeout 180;
+        {{ .directive }}_pass {{ .passproto }}://{{ .domain }}-upstream;
+        {{- template "proxy" . }}
     }
-}
\ No newline at end of file
+    {{- end }}
+}
+{{- define "proxy" }}
+        {{ .directive }}_set_header Host {{ if .host }}"{{ .host }}"{{ else }}$host{{ end }};
+        {{ .directive }}_set_header X-Real-IP $remote_addr;
+        {{ .directive }}_set_header X-Forwarded-For $remote_addr;
+        {{- $directive := .directive }}
+        {{- range .reqheader }}
+        {{ $directive }}_set_header {{ .Name }} "{{ .Value }}";
+        {{- end }}
+        {{- if and .websocket (eq .directive "proxy") }}
+        proxy_http_version 1.1;
+        proxy_set_header Upgrade $http_upgrade;
+        proxy_set_header Connection "upgrade";
+        {{- end }}
+        {{ .directive }}_connect_timeout {{ .timeouts.Connect }}s;
+        {{ .directive }}_send_timeout {{ .timeouts.Send }}s;
+        {{ .directive }}_read_timeout {{ .timeouts.Read }}s;
+{{- end }}
\ No newline at end of file
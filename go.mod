@@ -11,6 +11,7 @@ require (
 	github.com/slack-go/slack v0.19.0
 	github.com/spf13/viper v1.21.0
 	go.mongodb.org/mongo-driver v1.17.9
+	golang.org/x/crypto v0.48.0
 )
 
 require (
@@ -54,7 +55,6 @@ require (
 	go.uber.org/atomic v1.11.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
-	golang.org/x/crypto v0.48.0 // indirect
 	golang.org/x/exp v0.0.0-20260218203240-3dfff04db8fa // indirect
 	golang.org/x/net v0.51.0 // indirect
 	golang.org/x/sync v0.19.0 // indirect
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/1k-off/dev-helper-bot/internal/cache"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
 	"github.com/1k-off/dev-helper-bot/internal/handlers"
 	"github.com/rs/zerolog/log"
 	"github.com/shomali11/slacker"
@@ -22,6 +23,7 @@ type Config struct {
 	CmdHandler     *handlers.Handler
 	ChannelName    string
 	Cache          cache.Cache
+	emails         *emailCache
 }
 
 var (
@@ -41,6 +43,7 @@ func New(authToken, appToken, channelName string, adminUserEmails []string, cmdH
 		slacker.WithoutAllFormatting(),
 	)
 	adminUserIds := getAdminUserIDs(bot.APIClient(), adminUserEmails)
+	cmdHandler.AdminUserIDs = adminUserIds
 
 	return &Config{
 		bot:            bot,
@@ -52,11 +55,16 @@ func New(authToken, appToken, channelName string, adminUserEmails []string, cmdH
 		AdminUserIDs:   adminUserIds,
 		ChannelName:    channelName,
 		Cache:          cache,
+		emails:         newEmailCache(),
 	}
 }
 
 func (b *Config) Run() error {
+	b.issueMissingCredentials()
 	b.defineDomainCronJobs()
+	b.defineDomainHealthCronJobs()
+	b.defineDomainLogsCronJobs()
+	b.defineDomainVpnCronJobs()
 	b.defineVpnEUCronJobs()
 	b.defineVpnCommands()
 	b.defineDomainCommands()
@@ -267,14 +275,43 @@ func (b *Config) defineVpnCommands() {
 
 func (b *Config) defineDomainCommands() {
 	createCommand := &slacker.CommandDefinition{
-		Description: "Create a domain for provided IP.",
-		Examples:    []string{"create 127.0.0.1"},
+		Description: "Create a domain for provided IP. Optional name creates an additional domain, e.g. j-doe-api. Use `name <label>` to choose the subdomain label yourself. Options: port=<port>, ssl=true|false, auth=true|false, preset=<preset>. Admins can add `--as @user` to create a domain for another user.",
+		Examples:    []string{"domain create 127.0.0.1", "domain create api 127.0.0.1", "domain create 127.0.0.1 name qa-env", "domain create 127.0.0.1 port=3000 ssl=true auth=false", "domain create 127.0.0.1 preset=nextjs", "domain create 127.0.0.1 --as @user"},
 		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
-			ip := request.Param("IP")
-			userId := botCtx.Event().UserID
-			id := strings.TrimSuffix(strings.TrimPrefix(userId, "<@"), ">")
-			userName := getUserFriendlyName(botCtx.APIClient(), id)
-			d, err := b.CmdHandler.DomainCreate(id, userName, ip)
+			id, args, err := b.actingUser(botCtx.Event().UserID, commandArgs(request.Param("IP")))
+			if err != nil {
+				reply(botCtx, response, fmt.Sprintf("Error creating domain. %v", err))
+				return
+			}
+			label, args := popKeyword(args, "name")
+			var options, positional []string
+			for _, arg := range args {
+				if handlers.IsDomainOption(arg) {
+					options = append(options, arg)
+				} else {
+					positional = append(positional, arg)
+				}
+			}
+			var opts handlers.DomainOptions
+			switch len(positional) {
+			case 1:
+				opts = handlers.NewDomainOptions(positional[0])
+			case 2:
+				opts = handlers.NewDomainOptions(positional[1])
+				opts.Name = positional[0]
+			default:
+				reply(botCtx, response, "Usage: `domain create [name] <IP> [name <label>] [port=<port>] [ssl=true|false] [auth=true|false] [preset=<preset>]`")
+				return
+			}
+			opts.Label = label
+			for _, option := range options {
+				if err = opts.Set(option); err != nil {
+					reply(botCtx, response, fmt.Sprintf("Error creating domain. %v", err))
+					return
+				}
+			}
+			userNames := getUserNames(botCtx.APIClient(), id)
+			d, err := b.CmdHandler.DomainCreate(botCtx.Event().UserID, id, userNames, opts)
 			if err != nil {
 				log.Err(err).Msgf("Error creating domain. Request: %v, user: %v", botCtx.Event().Text, botCtx.Event().UserID)
 				replyErr := response.Reply(fmt.Sprintf("Error creating domain. %v", err), slacker.WithThreadReply(true))
@@ -283,10 +320,15 @@ func (b *Config) defineDomainCommands() {
 				}
 				return
 			}
-			err = response.Reply(
-				fmt.Sprintf("Created domain %s with IP %s. Scheduled delete date: %s.", d.FQDN, d.IP, d.DeleteAt.In(b.CmdHandler.Timezone).Format(messageTimeFormat)),
-				slacker.WithThreadReply(true),
-			)
+			b.sendBasicAuthCredentials(botCtx.APIClient(), d)
+			if id != botCtx.Event().UserID {
+				b.auditAdminAction(botCtx.APIClient(), botCtx.Event().UserID, []string{id}, fmt.Sprintf("created domain %s with IP %s on behalf of <@%s>", d.FQDN, d.IP, id))
+			}
+			message := fmt.Sprintf("Created domain %s with IP %s. Scheduled delete date: %s.", d.FQDN, d.IP, d.DeleteAt.In(b.CmdHandler.Timezone).Format(messageTimeFormat))
+			if warning := b.CmdHandler.IpConflictWarning(id, d); warning != "" {
+				message += "\n" + warning
+			}
+			err = response.Reply(message, slacker.WithThreadReply(true))
 			if err != nil {
 				log.Err(err).Msgf("Error sending reply. Request: %v, user: %v", botCtx.Event().Text, botCtx.Event().UserID)
 				return
@@ -295,13 +337,24 @@ func (b *Config) defineDomainCommands() {
 	}
 
 	updateCommand := &slacker.CommandDefinition{
-		Description: "Update parameter for domain. Available params: expire (no value), basic-auth(true|false), ip (<ip>), full-ssl(true|false), port <port>.",
-		Examples:    []string{"update <param> <value>", "update expire", "update basic-auth true", "update ip 127.0.0.1", "update port 3000", "update full-ssl true"},
+		Description: "Update parameter for domain. Available params: expire ([duration|date]), basic-auth(true|false|rotate), ip (<ip>|vpn), full-ssl(true|false), port <port>, preset (<preset>|none), websocket (true|false), upstream-protocol (http|https|h2c|grpc), timeout (<duration>|default), max-body (<size>|default), logs (true|false), wildcard (true|false). Put the domain name first if you have several domains. Admins can add `--as @user` to update a domain of another user.",
+		Examples:    []string{"domain update <param> <value>", "domain update expire", "domain update expire 5d", "domain update expire 2025-12-01", "domain update basic-auth true", "domain update basic-auth rotate", "domain update ip 127.0.0.1", "domain update ip vpn", "domain update port 3000", "domain update full-ssl true", "domain update preset nextjs", "domain update websocket true", "domain update upstream-protocol grpc", "domain update timeout 10m", "domain update max-body 50m", "domain update logs true", "domain update wildcard true", "domain update api port 8080", "domain update api port 8080 --as @user"},
 		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
-			param := request.StringParam("param", "expire")
-			value := request.StringParam("value", "")
-			userId := botCtx.Event().UserID
-			result, err := b.CmdHandler.DomainUpdate(userId, param, value)
+			var selector, param, value string
+			userId, args, err := b.actingUser(botCtx.Event().UserID, commandArgs(request.Param("param"), request.Param("value")))
+			if err != nil {
+				reply(botCtx, response, fmt.Sprintf("Error updating domain. %v", err))
+				return
+			}
+			if len(args) > 0 && !handlers.IsDomainUpdateParam(args[0]) {
+				selector, args = args[0], args[1:]
+			}
+			param = "expire"
+			if len(args) > 0 {
+				param = args[0]
+				value = strings.Join(args[1:], " ")
+			}
+			d, err := b.CmdHandler.DomainUpdate(botCtx.Event().UserID, userId, selector, param, value)
 			if err != nil {
 				log.Err(err).Msgf("Error updating domain. Request: %v, user: %v", botCtx.Event().Text, botCtx.Event().UserID)
 				replyErr := response.Reply(fmt.Sprintf("Error updating domain. %v", err), slacker.WithThreadReply(true))
@@ -310,7 +363,19 @@ func (b *Config) defineDomainCommands() {
 				}
 				return
 			}
-			err = response.Reply(result, slacker.WithThreadReply(true))
+			b.sendBasicAuthCredentials(botCtx.APIClient(), d)
+			if userId != botCtx.Event().UserID {
+				b.auditAdminAction(botCtx.APIClient(), botCtx.Event().UserID, d.Owners(), fmt.Sprintf("updated domain %s (%s %s) on behalf of <@%s>", d.FQDN, param, value, userId))
+			}
+			message := fmt.Sprintf("Updated domain %s", d.FQDN)
+			if param == "ip" && value == entities.IpModeVpn {
+				message += ". " + b.followVpnNow(botCtx.APIClient(), d)
+			} else if param == "ip" {
+				if warning := b.CmdHandler.IpConflictWarning(userId, d); warning != "" {
+					message += "\n" + warning
+				}
+			}
+			err = response.Reply(message, slacker.WithThreadReply(true))
 			if err != nil {
 				log.Err(err).Msgf("Error sending reply. Request: %v, user: %v", botCtx.Event().Text, botCtx.Event().UserID)
 				return
@@ -318,12 +383,32 @@ func (b *Config) defineDomainCommands() {
 		},
 	}
 
-	deleteCommand := &slacker.CommandDefinition{
-		Description: "Delete domain assigned to you.",
-		Examples:    []string{"delete"},
+	verifyCommand := &slacker.CommandDefinition{
+		Description: "Prove that you control an IP: the bot fetches the token it gave you from http://<ip>:<port>/.well-known/dev-helper/<token>. Port 80 is used by default.",
+		Examples:    []string{"domain verify 10.0.0.5", "domain verify 10.0.0.5:8080"},
 		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
 			userId := botCtx.Event().UserID
-			result, err := b.CmdHandler.DomainDelete(userId)
+			result, err := b.CmdHandler.DomainVerifyIp(userId, request.Param("target"))
+			if err != nil {
+				log.Err(err).Msgf("Error verifying IP. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error verifying IP. %v", err))
+				return
+			}
+			reply(botCtx, response, result)
+		},
+	}
+
+	deleteCommand := &slacker.CommandDefinition{
+		Description: "Delete domain assigned to you. Put the domain name if you have several domains. Admins can add `--as @user` to delete a domain of another user.",
+		Examples:    []string{"domain delete", "domain delete api", "domain delete api --as @user"},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId, args, err := b.actingUser(botCtx.Event().UserID, commandArgs(request.Param("name")))
+			if err != nil {
+				reply(botCtx, response, fmt.Sprintf("Error deleting domain. %v", err))
+				return
+			}
+			selector := strings.Join(args, " ")
+			d, err := b.CmdHandler.DomainDelete(userId, selector)
 			if err != nil {
 				log.Err(err).Msgf("Error deleting domain. Request: %v, user: %v", botCtx.Event().Text, botCtx.Event().UserID)
 				replyErr := response.Reply(fmt.Sprintf("Error deleting domain. %v", err), slacker.WithThreadReply(true))
@@ -332,7 +417,11 @@ func (b *Config) defineDomainCommands() {
 				}
 				return
 			}
-			err = response.Reply(result, slacker.WithThreadReply(true))
+			b.clearDomainFlags(d)
+			if userId != botCtx.Event().UserID {
+				b.auditAdminAction(botCtx.APIClient(), botCtx.Event().UserID, d.Owners(), fmt.Sprintf("deleted domain %s on behalf of <@%s>", d.FQDN, userId))
+			}
+			err = response.Reply(fmt.Sprintf("Deleted domain %s", d.FQDN), slacker.WithThreadReply(true))
 			if err != nil {
 				log.Err(err).Msgf("Error sending reply. Request: %v, user: %v", botCtx.Event().Text, botCtx.Event().UserID)
 				return
@@ -340,9 +429,536 @@ func (b *Config) defineDomainCommands() {
 		},
 	}
 
+	routeCommand := &slacker.CommandDefinition{
+		Description: "Manage path-based routes of your domain. Requests with the path prefix go to a separate upstream.",
+		Examples:    []string{"domain route add /api 10.0.0.5:8080", "domain route add api /api 10.0.0.5:8080", "domain route remove /api", "domain route list"},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			var result string
+			var err error
+			userId := botCtx.Event().UserID
+			args := commandArgs(request.Param("action"), request.Param("args"))
+			if len(args) == 0 {
+				reply(botCtx, response, "Usage: `domain route add|remove|list [name] <path> <ip:port>`")
+				return
+			}
+			action, args := args[0], args[1:]
+			var selector string
+			if len(args) > 0 && !strings.HasPrefix(args[0], "/") {
+				selector, args = args[0], args[1:]
+			}
+			switch {
+			case action == "add" && len(args) == 2:
+				result, err = b.CmdHandler.DomainRouteAdd(userId, selector, args[0], args[1])
+			case action == "remove" && len(args) == 1:
+				result, err = b.CmdHandler.DomainRouteRemove(userId, selector, args[0])
+			case action == "list" && len(args) == 0:
+				result, err = b.CmdHandler.DomainRouteList(userId, selector)
+			default:
+				reply(botCtx, response, "Usage: `domain route add|remove|list [name] <path> <ip:port>`")
+				return
+			}
+			if err != nil {
+				log.Err(err).Msgf("Error managing domain routes. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error managing domain routes. %v", err))
+				return
+			}
+			reply(botCtx, response, result)
+		},
+	}
+
+	aliasCommand := &slacker.CommandDefinition{
+		Description: "Manage extra hostnames of your domain: labels under the parent domain or external hostnames approved by admins.",
+		Examples:    []string{"domain alias add j-doe-auth", "domain alias add api j-doe-api-auth", "domain alias remove j-doe-auth", "domain alias list"},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			var result string
+			var err error
+			usage := "Usage: `domain alias add|remove [name] <label or hostname>`, `domain alias list [name]`"
+			userId := botCtx.Event().UserID
+			args := commandArgs(request.Param("action"), request.Param("args"))
+			if len(args) == 0 {
+				reply(botCtx, response, usage)
+				return
+			}
+			action, args := args[0], args[1:]
+			var selector string
+			if (action == "list" && len(args) == 1) || len(args) == 2 {
+				selector, args = args[0], args[1:]
+			}
+			switch {
+			case action == "add" && len(args) == 1:
+				result, err = b.CmdHandler.DomainAliasAdd(userId, selector, args[0])
+			case action == "remove" && len(args) == 1:
+				result, err = b.CmdHandler.DomainAliasRemove(userId, selector, args[0])
+			case action == "list" && len(args) == 0:
+				result, err = b.CmdHandler.DomainAliasList(userId, selector)
+			default:
+				reply(botCtx, response, usage)
+				return
+			}
+			if err != nil {
+				log.Err(err).Msgf("Error managing domain aliases. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error managing domain aliases. %v", err))
+				return
+			}
+			reply(botCtx, response, result)
+		},
+	}
+
+	headerCommand := &slacker.CommandDefinition{
+		Description: "Manage custom request headers sent to your upstream and response headers sent to viewers. Use `any` for the * value of Access-Control headers.",
+		Examples: []string{
+			"domain header set response X-Robots-Tag noindex",
+			"domain header set response Access-Control-Allow-Origin any",
+			"domain header set api request Host localhost",
+			"domain header unset response X-Robots-Tag",
+			"domain header list",
+		},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			var result string
+			var err error
+			usage := "Usage: `domain header set [name] request|response <header> <value>`, `domain header unset [name] request|response <header>`, `domain header list [name]`"
+			userId := botCtx.Event().UserID
+			// header values are used as typed, so links are not turned into host names
+			var args []string
+			for _, arg := range strings.Fields(request.Param("action") + " " + request.Param("args")) {
+				args = append(args, slackLinkText(arg))
+			}
+			if len(args) == 0 {
+				reply(botCtx, response, usage)
+				return
+			}
+			action, args := args[0], args[1:]
+			var selector string
+			switch {
+			case action == "list" && len(args) == 1:
+				selector, args = args[0], args[1:]
+			case action != "list" && len(args) > 0 && !handlers.IsHeaderKind(args[0]):
+				selector, args = args[0], args[1:]
+			}
+			switch {
+			case action == "set" && len(args) >= 3:
+				result, err = b.CmdHandler.DomainHeaderSet(userId, selector, args[0], args[1], strings.Join(args[2:], " "))
+			case action == "unset" && len(args) == 2:
+				result, err = b.CmdHandler.DomainHeaderUnset(userId, selector, args[0], args[1])
+			case action == "list" && len(args) == 0:
+				result, err = b.CmdHandler.DomainHeaderList(userId, selector)
+			default:
+				reply(botCtx, response, usage)
+				return
+			}
+			if err != nil {
+				log.Err(err).Msgf("Error managing domain headers. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error managing domain headers. %v", err))
+				return
+			}
+			reply(botCtx, response, result)
+		},
+	}
+
+	shareCommand := &slacker.CommandDefinition{
+		Description: "Add a co-owner to your domain. Co-owners can update the domain and get expiry reminders.",
+		Examples:    []string{"domain share @user", "domain share api @user"},
+		Handler:     b.domainShareHandler(true),
+	}
+
+	unshareCommand := &slacker.CommandDefinition{
+		Description: "Remove a co-owner from your domain.",
+		Examples:    []string{"domain unshare @user", "domain unshare api @user"},
+		Handler:     b.domainShareHandler(false),
+	}
+
+	transferCommand := &slacker.CommandDefinition{
+		Description: "[ADMIN] Make another user the owner of a domain. Basic auth credentials are regenerated and sent to the new owners.",
+		Examples:    []string{"domain transfer j-doe.domain.tld @user"},
+		AuthorizationFunc: func(botCtx slacker.BotContext, request slacker.Request) bool {
+			return contains(b.AdminUserIDs, botCtx.Event().UserID)
+		},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId := botCtx.Event().UserID
+			args := commandArgs(request.Param("args"))
+			var newOwnerId string
+			ok := len(args) == 2
+			if ok {
+				newOwnerId, ok = extractUserId(args[1])
+			}
+			if !ok {
+				reply(botCtx, response, "Usage: `domain transfer <fqdn> @user`")
+				return
+			}
+			client := botCtx.APIClient()
+			d, previousOwnerId, err := b.CmdHandler.DomainTransfer(userId, args[0], newOwnerId, getUserNames(client, newOwnerId))
+			if err != nil {
+				log.Err(err).Msgf("Error transferring domain. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error transferring domain. %v", err))
+				return
+			}
+			b.sendBasicAuthCredentials(client, d)
+			action := fmt.Sprintf("transferred domain %s from <@%s> to <@%s>", d.FQDN, previousOwnerId, newOwnerId)
+			if previousOwnerId != userId {
+				b.auditAdminAction(client, userId, []string{previousOwnerId, newOwnerId}, action)
+			} else {
+				_, _, err = client.PostMessage(
+					newOwnerId,
+					slack.MsgOptionText(fmt.Sprintf("<@%s> %s. You are the owner now.", userId, action), false),
+				)
+				if err != nil {
+					log.Err(err).Msgf("Error sending direct message. Request: %v, user: %v", botCtx.Event().Text, newOwnerId)
+				}
+			}
+			reply(botCtx, response, fmt.Sprintf("Domain %s transferred to <@%s>.", d.FQDN, newOwnerId))
+		},
+	}
+
+	listCommand := &slacker.CommandDefinition{
+		Description: fmt.Sprintf("[ADMIN] List all domains, %d per page. Filters: owner=@user, subnet=<cidr>, expires-before=<duration|date>, auth=true|false, ssl=true|false. Sort with sort=fqdn|owner|ip|created|expires. Add `csv` to get all matching domains as a file.", handlers.DomainListPageSize),
+		Examples: []string{
+			"domain list",
+			"domain list page=2",
+			"domain list owner=@user",
+			"domain list subnet=10.0.0.0/24 sort=expires",
+			"domain list expires-before=3d auth=false",
+			"domain list ssl=true csv",
+		},
+		AuthorizationFunc: func(botCtx slacker.BotContext, request slacker.Request) bool {
+			return contains(b.AdminUserIDs, botCtx.Event().UserID)
+		},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId := botCtx.Event().UserID
+			args := commandArgs(request.Param("args"))
+			for i, arg := range args {
+				if value, ok := strings.CutPrefix(arg, "owner="); ok {
+					if id, ok := extractUserId(value); ok {
+						args[i] = "owner=" + id
+					}
+				}
+			}
+			query, err := b.CmdHandler.ParseDomainListQuery(args)
+			if err != nil {
+				reply(botCtx, response, fmt.Sprintf("Error listing domains. %v", err))
+				return
+			}
+			domains, total, err := b.CmdHandler.DomainList(query)
+			if err != nil {
+				log.Err(err).Msgf("Error listing domains. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error listing domains. %v", err))
+				return
+			}
+			if total == 0 {
+				reply(botCtx, response, "No domains found.")
+				return
+			}
+			if query.CSV {
+				content, err := b.CmdHandler.DomainListCSV(domains)
+				if err == nil {
+					err = uploadFile(botCtx, "domains.csv", fmt.Sprintf("Domains (%d)", total), "csv", content)
+				}
+				if err != nil {
+					log.Err(err).Msgf("Error exporting domains. Request: %v, user: %v", botCtx.Event().Text, userId)
+					reply(botCtx, response, fmt.Sprintf("Error exporting domains. %v", err))
+				}
+				return
+			}
+			reply(botCtx, response, domainListMessage(domains, total, query.Page, args, b.CmdHandler.Timezone))
+		},
+	}
+
+	whoisCommand := &slacker.CommandDefinition{
+		Description: "[ADMIN] Show owners and recent changes of domains using an IP or a hostname.",
+		Examples:    []string{"whois 10.0.0.5", "whois j-doe.domain.tld"},
+		AuthorizationFunc: func(botCtx slacker.BotContext, request slacker.Request) bool {
+			return contains(b.AdminUserIDs, botCtx.Event().UserID)
+		},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			result, err := b.CmdHandler.Whois(cleanSlackLink(request.Param("query")))
+			if err != nil {
+				log.Err(err).Msgf("Error looking up owner. Request: %v, user: %v", botCtx.Event().Text, botCtx.Event().UserID)
+				reply(botCtx, response, fmt.Sprintf("Error looking up owner. %v", err))
+				return
+			}
+			reply(botCtx, response, result)
+		},
+	}
+
+	reservationCommand := &slacker.CommandDefinition{
+		Description: "[ADMIN] List names of deleted domains kept for their previous owners or release a name.",
+		Examples:    []string{"domain reservation list", "domain reservation release j-doe.domain.tld"},
+		AuthorizationFunc: func(botCtx slacker.BotContext, request slacker.Request) bool {
+			return contains(b.AdminUserIDs, botCtx.Event().UserID)
+		},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId := botCtx.Event().UserID
+			args := commandArgs(request.Param("args"))
+			switch {
+			case len(args) == 1 && args[0] == "list":
+				reservations, err := b.CmdHandler.DomainReservationList()
+				if err != nil {
+					log.Err(err).Msgf("Error listing reservations. Request: %v, user: %v", botCtx.Event().Text, userId)
+					reply(botCtx, response, fmt.Sprintf("Error listing reservations. %v", err))
+					return
+				}
+				if len(reservations) == 0 {
+					reply(botCtx, response, "There are no reserved domain names.")
+					return
+				}
+				lines := []string{"Reserved domain names:"}
+				for _, r := range reservations {
+					lines = append(lines, fmt.Sprintf("%s for <@%s> until %s", r.FQDN, r.UserId, r.ExpiresAt.In(b.CmdHandler.Timezone).Format(messageTimeFormat)))
+				}
+				reply(botCtx, response, strings.Join(lines, "\n"))
+			case len(args) == 2 && args[0] == "release":
+				if err := b.CmdHandler.DomainReservationRelease(args[1]); err != nil {
+					log.Err(err).Msgf("Error releasing reservation. Request: %v, user: %v", botCtx.Event().Text, userId)
+					reply(botCtx, response, fmt.Sprintf("Error releasing reservation. %v", err))
+					return
+				}
+				reply(botCtx, response, fmt.Sprintf("Domain name %s is available to everyone.", args[1]))
+			default:
+				reply(botCtx, response, "Usage: `domain reservation list|release <fqdn>`")
+			}
+		},
+	}
+
+	pauseCommand := &slacker.CommandDefinition{
+		Description: "Pause your domain: it keeps the name and expiration date but shows a holding page instead of your site.",
+		Examples:    []string{"domain pause", "domain pause api"},
+		Handler:     b.domainPauseHandler(true),
+	}
+
+	resumeCommand := &slacker.CommandDefinition{
+		Description: "Resume your paused domain.",
+		Examples:    []string{"domain resume", "domain resume api"},
+		Handler:     b.domainPauseHandler(false),
+	}
+
+	infoCommand := &slacker.CommandDefinition{
+		Description: "Show settings of your domain with buttons to extend it, toggle basic auth or delete it. Put the domain name if you have several domains.",
+		Examples:    []string{"domain info", "domain info api"},
+		BlockID:     domainInfoBlockId,
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId := botCtx.Event().UserID
+			info, err := b.CmdHandler.DomainInfo(userId, request.Param("name"))
+			if err != nil {
+				log.Err(err).Msgf("Error getting domain info. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error getting domain info. %v", err))
+				return
+			}
+			blocks := domainInfoBlocks(info, userLocation(botCtx.APIClient(), userId, b.CmdHandler.Timezone))
+			err = response.Reply(info.Domain.FQDN, slacker.WithBlocks(blocks), slacker.WithThreadReply(true))
+			if err != nil {
+				log.Err(err).Msgf("Error sending reply. Request: %v, user: %v", botCtx.Event().Text, userId)
+			}
+		},
+		Interactive: b.domainInfoInteractive,
+	}
+
+	historyCommand := &slacker.CommandDefinition{
+		Description: "Show the last changes of your domain with their revision numbers. Put the domain name if you have several domains.",
+		Examples:    []string{"domain history", "domain history api"},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId := botCtx.Event().UserID
+			result, err := b.CmdHandler.DomainHistory(userId, request.Param("name"))
+			if err != nil {
+				log.Err(err).Msgf("Error getting domain history. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error getting domain history. %v", err))
+				return
+			}
+			reply(botCtx, response, result)
+		},
+	}
+
+	rollbackCommand := &slacker.CommandDefinition{
+		Description: "Restore settings of your domain from a revision listed by `domain history`. Owners and the expiration date are not changed.",
+		Examples:    []string{"domain rollback 3", "domain rollback api 3"},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId := botCtx.Event().UserID
+			args := commandArgs(request.Param("args"))
+			var selector string
+			if len(args) == 2 {
+				selector, args = args[0], args[1:]
+			}
+			var rev int
+			var err error
+			if len(args) == 1 {
+				rev, err = strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+			}
+			if len(args) != 1 || err != nil || rev < 1 {
+				reply(botCtx, response, "Usage: `domain rollback [name] <revision>`")
+				return
+			}
+			d, err := b.CmdHandler.DomainRollback(userId, selector, rev)
+			if err != nil {
+				log.Err(err).Msgf("Error rolling back domain. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error rolling back domain. %v", err))
+				return
+			}
+			b.sendBasicAuthCredentials(botCtx.APIClient(), d)
+			reply(botCtx, response, fmt.Sprintf("Domain %s is rolled back to revision #%d", d.FQDN, rev))
+		},
+	}
+
+	logsCommand := &slacker.CommandDefinition{
+		Description: "Get the tail of the access log of your domain, or of the error log with `errors`. Enable logs first with `domain update logs true`.",
+		Examples:    []string{"domain logs", "domain logs 100", "domain logs errors", "domain logs api 20 errors"},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId := botCtx.Event().UserID
+			args := commandArgs(request.Param("args"))
+			var selector string
+			var lines int
+			var errors bool
+			if len(args) > 0 && args[len(args)-1] == "errors" {
+				errors, args = true, args[:len(args)-1]
+			}
+			if len(args) > 0 {
+				if n, err := strconv.Atoi(args[len(args)-1]); err == nil {
+					lines, args = n, args[:len(args)-1]
+				}
+			}
+			if len(args) > 1 {
+				reply(botCtx, response, "Usage: `domain logs [name] [lines] [errors]`")
+				return
+			}
+			if len(args) == 1 {
+				selector = args[0]
+			}
+			d, content, err := b.CmdHandler.DomainLogs(userId, selector, lines, errors)
+			if err != nil {
+				log.Err(err).Msgf("Error getting domain logs. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error getting domain logs. %v", err))
+				return
+			}
+			if content == "" {
+				reply(botCtx, response, fmt.Sprintf("No log lines for %s yet.", d.FQDN))
+				return
+			}
+			kind := "access"
+			if errors {
+				kind = "error"
+			}
+			err = uploadFile(botCtx, fmt.Sprintf("%s.%s.log", d.FQDN, kind), fmt.Sprintf("%s %s log", d.FQDN, kind), "text", content)
+			if err != nil {
+				log.Err(err).Msgf("Error uploading domain logs. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error uploading domain logs. %v", err))
+			}
+		},
+	}
+
+	accessCommand := &slacker.CommandDefinition{
+		Description: "Manage who can open your domain: allowed networks, office networks without basic auth and VPN-only access.",
+		Examples: []string{
+			"domain access allow 203.0.113.7",
+			"domain access api allow 203.0.113.0/24",
+			"domain access disallow 203.0.113.7",
+			"domain access office-bypass true",
+			"domain access vpn-only true",
+			"domain access show",
+			"domain access reset",
+		},
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			userId := botCtx.Event().UserID
+			args := commandArgs(request.Param("args"))
+			var selector string
+			if len(args) > 0 && !handlers.IsDomainAccessAction(args[0]) {
+				selector, args = args[0], args[1:]
+			}
+			var action, value string
+			switch {
+			case len(args) == 1 && (args[0] == "show" || args[0] == "reset"):
+				action = args[0]
+			case len(args) == 2 && handlers.IsDomainAccessAction(args[0]):
+				action, value = args[0], args[1]
+			default:
+				reply(botCtx, response, "Usage: `domain access [name] allow|disallow <ip or cidr>`, `domain access [name] office-bypass|vpn-only true|false`, `domain access [name] show|reset`")
+				return
+			}
+			result, err := b.CmdHandler.DomainAccess(userId, selector, action, value)
+			if err != nil {
+				log.Err(err).Msgf("Error managing domain access. Request: %v, user: %v", botCtx.Event().Text, userId)
+				reply(botCtx, response, fmt.Sprintf("Error managing domain access. %v", err))
+				return
+			}
+			reply(botCtx, response, result)
+		},
+	}
+
 	b.bot.Command("domain create <IP>", createCommand)
 	b.bot.Command("domain update <param> <value>", updateCommand)
-	b.bot.Command("domain delete", deleteCommand)
+	b.bot.Command("domain verify <target>", verifyCommand)
+	b.bot.Command("domain delete <name>", deleteCommand)
+	b.bot.Command("domain route <action> <args>", routeCommand)
+	b.bot.Command("domain header <action> <args>", headerCommand)
+	b.bot.Command("domain alias <action> <args>", aliasCommand)
+	b.bot.Command("domain share <args>", shareCommand)
+	b.bot.Command("domain unshare <args>", unshareCommand)
+	b.bot.Command("domain access <args>", accessCommand)
+	b.bot.Command("domain transfer <args>", transferCommand)
+	b.bot.Command("domain list <args>", listCommand)
+	b.bot.Command("domain reservation <args>", reservationCommand)
+	b.bot.Command("whois <query>", whoisCommand)
+	b.bot.Command("domain info <name>", infoCommand)
+	b.bot.Command("domain history <name>", historyCommand)
+	b.bot.Command("domain rollback <args>", rollbackCommand)
+	b.bot.Command("domain logs <args>", logsCommand)
+	b.bot.Command("domain pause <name>", pauseCommand)
+	b.bot.Command("domain resume <name>", resumeCommand)
+}
+
+// domainShareHandler returns the handler for domain share and unshare commands
+func (b *Config) domainShareHandler(share bool) func(slacker.BotContext, slacker.Request, slacker.ResponseWriter) {
+	return func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+		userId := botCtx.Event().UserID
+		args := commandArgs(request.Param("args"))
+		var selector string
+		if len(args) == 2 {
+			selector, args = args[0], args[1:]
+		}
+		var coOwnerId string
+		ok := len(args) == 1
+		if ok {
+			coOwnerId, ok = extractUserId(args[0])
+		}
+		if !ok {
+			reply(botCtx, response, "Usage: `domain share|unshare [name] @user`")
+			return
+		}
+
+		action, shareFunc := "unshared with", b.CmdHandler.DomainUnshare
+		if share {
+			action, shareFunc = "shared with", b.CmdHandler.DomainShare
+		}
+		d, err := shareFunc(userId, selector, coOwnerId)
+		if err != nil {
+			log.Err(err).Msgf("Error sharing domain. Request: %v, user: %v", botCtx.Event().Text, userId)
+			reply(botCtx, response, fmt.Sprintf("Error sharing domain. %v", err))
+			return
+		}
+		_, _, err = botCtx.APIClient().PostMessage(
+			coOwnerId,
+			slack.MsgOptionText(fmt.Sprintf("Domain %s was %s you by <@%s>.", d.FQDN, action, userId), false),
+		)
+		if err != nil {
+			log.Err(err).Msgf("Error sending direct message. Request: %v, user: %v", botCtx.Event().Text, coOwnerId)
+		}
+		reply(botCtx, response, fmt.Sprintf("Domain %s %s <@%s>.", d.FQDN, action, coOwnerId))
+	}
+}
+
+// domainPauseHandler returns the handler for domain pause and resume commands
+func (b *Config) domainPauseHandler(pause bool) func(slacker.BotContext, slacker.Request, slacker.ResponseWriter) {
+	return func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+		userId := botCtx.Event().UserID
+		selector := strings.Join(commandArgs(request.Param("name")), " ")
+		action, pauseFunc := "resumed", b.CmdHandler.DomainResume
+		if pause {
+			action, pauseFunc = "paused", b.CmdHandler.DomainPause
+		}
+		d, err := pauseFunc(userId, selector)
+		if err != nil {
+			log.Err(err).Msgf("Error changing domain state. Request: %v, user: %v", botCtx.Event().Text, userId)
+			reply(botCtx, response, fmt.Sprintf("Error changing domain state. %v", err))
+			return
+		}
+		reply(botCtx, response, fmt.Sprintf("Domain %s %s. It will be deleted at %s.", d.FQDN, action, d.DeleteAt.In(b.CmdHandler.Timezone).Format(messageTimeFormat)))
+	}
 }
 
 func (b *Config) defineVpnEUCommands() {
@@ -3,4 +3,5 @@ package bot
 const (
 	cacheNamespaceDomainNotified = "domain"
 	cacheNamespaceVpnEUNotified  = "vpneu"
+	cacheNamespaceDomainDown     = "domain-down"
 )
@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/handlers"
+	"github.com/rs/zerolog/log"
+	"github.com/shomali11/slacker"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	domainInfoBlockId      = "domain-info"
+	domainInfoActionExtend = "extend"
+	domainInfoActionAuth   = "toggle-auth"
+	domainInfoActionDelete = "delete"
+)
+
+// userLocation returns the timezone from the user's Slack profile or the fallback one.
+func userLocation(client *slack.Client, userId string, fallback *time.Location) *time.Location {
+	user, err := client.GetUserInfo(userId)
+	if err != nil {
+		log.Err(err).Msgf("Error getting user info. ID: %s", userId)
+		return fallback
+	}
+	loc, err := time.LoadLocation(user.TZ)
+	if err != nil || user.TZ == "" {
+		return fallback
+	}
+	return loc
+}
+
+// domainInfoBlocks renders the domain summary with buttons for the common updates.
+// Button values hold the FQDN, so the buttons act on this domain even if the user has several.
+func domainInfoBlocks(info *handlers.DomainInfo, loc *time.Location) []slack.Block {
+	d := info.Domain
+	field := func(name, value string) *slack.TextBlockObject {
+		return slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*\n%s", name, value), false, false)
+	}
+	auth := "off"
+	if d.BasicAuth {
+		auth = fmt.Sprintf("on, user `%s`", d.BasicAuthUser)
+	}
+	ip := d.IP
+	if d.IpMode == entities.IpModeVpn {
+		ip += " (follows VPN)"
+	}
+	fields := []*slack.TextBlockObject{
+		field("IP", ip),
+		field("Port", info.Port),
+		field("Scheme", info.Scheme),
+		field("Basic auth", auth),
+		field("Created", d.CreatedAt.In(loc).Format(messageTimeFormat)),
+		field("Deletes", d.DeleteAt.In(loc).Format(messageTimeFormat)),
+		field("Upstream", upstreamStatus(d, loc)),
+	}
+	if len(d.Aliases) > 0 {
+		fields = append(fields, field("Aliases", strings.Join(d.Aliases, "\n")))
+	}
+	if len(d.CoOwners) > 0 {
+		fields = append(fields, field("Co-owners", mentionAll(d.CoOwners)))
+	}
+
+	extend := slack.NewButtonBlockElement(domainInfoActionExtend, d.FQDN, slack.NewTextBlockObject(slack.PlainTextType, "Extend", false, false))
+	extend.Style = slack.StylePrimary
+	authLabel := "Enable basic auth"
+	if d.BasicAuth {
+		authLabel = "Disable basic auth"
+	}
+	toggleAuth := slack.NewButtonBlockElement(domainInfoActionAuth, d.FQDN, slack.NewTextBlockObject(slack.PlainTextType, authLabel, false, false))
+	remove := slack.NewButtonBlockElement(domainInfoActionDelete, d.FQDN, slack.NewTextBlockObject(slack.PlainTextType, "Delete", false, false))
+	remove.Style = slack.StyleDanger
+	remove.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Delete domain?", false, false),
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("%s and its config will be deleted.", d.FQDN), false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Delete", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+	)
+
+	return []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, d.FQDN, false, false)),
+		slack.NewSectionBlock(nil, fields, nil),
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("Owner <@%s>, times in %s", d.UserId, loc), false, false)),
+		slack.NewActionBlock(domainInfoBlockId, extend, toggleAuth, remove),
+	}
+}
+
+// upstreamStatus describes the last health check of the domain upstream.
+func upstreamStatus(d *entities.Domain, loc *time.Location) string {
+	switch {
+	case d.IsPaused():
+		return "paused"
+	case d.Health.CheckedAt.IsZero():
+		return "not checked yet"
+	case d.Health.Status == entities.HealthDown:
+		return fmt.Sprintf(":red_circle: unreachable since %s: %s", d.Health.CheckedAt.In(loc).Format(messageTimeFormat), d.Health.Error)
+	}
+	return fmt.Sprintf(":large_green_circle: reachable, %dms at %s", d.Health.Latency.Milliseconds(), d.Health.CheckedAt.In(loc).Format(messageTimeFormat))
+}
+
+// domainInfoInteractive handles the buttons of the domain summary and refreshes it.
+func (b *Config) domainInfoInteractive(botCtx slacker.InteractiveBotContext, request *socketmode.Request, callback *slack.InteractionCallback) {
+	botCtx.SocketModeClient().Ack(*request)
+	if len(callback.ActionCallback.BlockActions) == 0 {
+		return
+	}
+	client := botCtx.APIClient()
+	userId := callback.User.ID
+	action := callback.ActionCallback.BlockActions[0]
+	fqdn := action.Value
+
+	replyEphemeral := func(message string) {
+		_, _, err := client.PostMessage(callback.Channel.ID, slack.MsgOptionText(message, false), slack.MsgOptionResponseURL(callback.ResponseURL, slack.ResponseTypeEphemeral))
+		if err != nil {
+			log.Err(err).Msgf("Error sending reply. Action: %s, user: %s", action.ActionID, userId)
+		}
+	}
+
+	var err error
+	switch action.ActionID {
+	case domainInfoActionExtend:
+		_, err = b.CmdHandler.DomainUpdate(userId, userId, fqdn, "expire", "")
+	case domainInfoActionAuth:
+		var info *handlers.DomainInfo
+		if info, err = b.CmdHandler.DomainInfo(userId, fqdn); err == nil {
+			var d *entities.Domain
+			d, err = b.CmdHandler.DomainUpdate(userId, userId, fqdn, "basic-auth", strconv.FormatBool(!info.Domain.BasicAuth))
+			if err == nil {
+				b.sendBasicAuthCredentials(client, d)
+			}
+		}
+	case domainInfoActionDelete:
+		var d *entities.Domain
+		if d, err = b.CmdHandler.DomainDelete(userId, fqdn); err == nil {
+			b.clearDomainFlags(d)
+			_, _, err = client.PostMessage(callback.Channel.ID, slack.MsgOptionText(fmt.Sprintf("Deleted domain %s", d.FQDN), false), slack.MsgOptionReplaceOriginal(callback.ResponseURL))
+			if err != nil {
+				log.Err(err).Msgf("Error sending reply. Action: %s, user: %s", action.ActionID, userId)
+			}
+			return
+		}
+	default:
+		return
+	}
+	if err != nil {
+		log.Err(err).Msgf("Error handling domain info action. Action: %s, domain: %s, user: %s", action.ActionID, fqdn, userId)
+		replyEphemeral(fmt.Sprintf("Error updating domain. %v", err))
+		return
+	}
+
+	info, err := b.CmdHandler.DomainInfo(userId, fqdn)
+	if err != nil {
+		log.Err(err).Msgf("Error getting domain info. Domain: %s, user: %s", fqdn, userId)
+		replyEphemeral(fmt.Sprintf("Error getting domain info. %v", err))
+		return
+	}
+	blocks := domainInfoBlocks(info, userLocation(client, userId, b.CmdHandler.Timezone))
+	_, _, err = client.PostMessage(callback.Channel.ID, slack.MsgOptionText(info.Domain.FQDN, false), slack.MsgOptionBlocks(blocks...), slack.MsgOptionReplaceOriginal(callback.ResponseURL))
+	if err != nil {
+		log.Err(err).Msgf("Error sending reply. Action: %s, user: %s", action.ActionID, userId)
+	}
+}
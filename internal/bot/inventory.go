@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/handlers"
+	"strings"
+	"time"
+)
+
+// domainListMessage renders a page of the domain inventory with a hint for the next page.
+// args are the options of the command, they are repeated in the hint with the next page number.
+func domainListMessage(domains []*entities.Domain, total int64, page int, args []string, loc *time.Location) string {
+	pages := int((total + handlers.DomainListPageSize - 1) / handlers.DomainListPageSize)
+	if len(domains) == 0 {
+		return fmt.Sprintf("Page %d is empty, there are %d pages.", page, pages)
+	}
+	first := (page-1)*handlers.DomainListPageSize + 1
+	lines := []string{fmt.Sprintf("Domains %d-%d of %d:", first, first+len(domains)-1, total)}
+	for _, d := range domains {
+		upstream := d.IP
+		if d.Port != "" {
+			upstream += ":" + d.Port
+		}
+		var flags []string
+		if !d.BasicAuth {
+			flags = append(flags, "no auth")
+		}
+		if d.FullSsl {
+			flags = append(flags, "ssl")
+		}
+		if d.IsPaused() {
+			flags = append(flags, "paused")
+		}
+		if d.Health.Status == entities.HealthDown {
+			flags = append(flags, "down")
+		}
+		line := fmt.Sprintf("%s -> %s <@%s>, deletes %s", d.FQDN, upstream, d.UserId, d.DeleteAt.In(loc).Format(messageTimeFormat))
+		if len(flags) > 0 {
+			line += ", " + strings.Join(flags, ", ")
+		}
+		lines = append(lines, line)
+	}
+	if page < pages {
+		var options []string
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "page=") {
+				options = append(options, arg)
+			}
+		}
+		next := strings.Join(append(options, fmt.Sprintf("page=%d", page+1)), " ")
+		export := strings.Join(append(options, "csv"), " ")
+		lines = append(lines, fmt.Sprintf("Page %d of %d. Next page: `domain list %s`, all matching domains as a file: `domain list %s`", page, pages, next, export))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -3,8 +3,43 @@ package bot
 import (
 	"github.com/rs/zerolog/log"
 	"github.com/slack-go/slack"
+	"sync"
+	"time"
 )
 
+// userEmailCacheTtl is how long emails of Slack users are reused by jobs running every minute
+const userEmailCacheTtl = time.Hour
+
+// emailCache keeps emails of Slack users, so frequent jobs don't call the Slack API
+// for every user on every run. Failed lookups are not cached.
+type emailCache struct {
+	mu      sync.Mutex
+	entries map[string]emailCacheEntry
+}
+
+type emailCacheEntry struct {
+	email     string
+	expiresAt time.Time
+}
+
+func newEmailCache() *emailCache {
+	return &emailCache{entries: map[string]emailCacheEntry{}}
+}
+
+// get returns the email of the user from the cache or from Slack when it is missing or expired.
+func (c *emailCache) get(client *slack.Client, userId string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[userId]; ok && time.Now().Before(e.expiresAt) {
+		return e.email
+	}
+	email := getUserEmail(client, userId)
+	if email != "" {
+		c.entries[userId] = emailCacheEntry{email: email, expiresAt: time.Now().Add(userEmailCacheTtl)}
+	}
+	return email
+}
+
 func getAdminUserIDs(c *slack.Client, emails []string) []string {
 	var adminUserIDs []string
 	for _, email := range emails {
@@ -37,6 +72,17 @@ func getUserIdByEmail(c *slack.Client, email string) string {
 	return ""
 }
 
+// getUserNames returns the user's display name, real name and handle in the order
+// they should be tried when generating a subdomain.
+func getUserNames(c *slack.Client, userId string) []string {
+	user, err := c.GetUserInfo(userId)
+	if err != nil {
+		log.Error().Err(err).Msgf("ID: %s", userId)
+		return []string{userId}
+	}
+	return []string{user.Profile.DisplayName, user.RealName, user.Name}
+}
+
 func getUserFriendlyName(c *slack.Client, userId string) string {
 	user, err := c.GetUserInfo(userId)
 	if err != nil {
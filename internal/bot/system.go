@@ -2,6 +2,8 @@ package bot
 
 import (
 	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/handlers"
 	"github.com/rs/zerolog/log"
 	"github.com/shomali11/slacker"
 	"github.com/slack-go/slack"
@@ -16,16 +18,32 @@ import (
 // Month: The month the cron job should run on (1-12)
 // Day of week: The day of the week the cron job should run on (0-7, where both 0 and 7 represent Sunday)
 
-func (b *Config) defineDomainCronJobs() {
-	cronValue := "0 9 * * * *"
-	// get os env
-	debugModeString := os.Getenv("OOOPS_DEBUG")
-	debugMode, err := strconv.ParseBool(debugModeString)
+// isDebugMode reports whether the OOOPS_DEBUG env variable is set to true
+func isDebugMode() bool {
+	debugMode, err := strconv.ParseBool(os.Getenv("OOOPS_DEBUG"))
 	if err != nil {
-		debugMode = false
 		log.Err(err).Msg("Error parsing OOOPS_DEBUG env variable")
+		return false
+	}
+	return debugMode
+}
+
+// issueMissingCredentials sends basic auth credentials generated for domains created before
+// credentials were generated per domain, otherwise the owners would be locked out by the first
+// config change.
+func (b *Config) issueMissingCredentials() {
+	domains, err := b.CmdHandler.DomainIssueMissingCredentials()
+	if err != nil {
+		log.Err(err).Msg("Error issuing basic auth credentials")
 	}
-	if debugMode {
+	for _, d := range domains {
+		b.sendBasicAuthCredentials(b.bot.APIClient(), d)
+	}
+}
+
+func (b *Config) defineDomainCronJobs() {
+	cronValue := "0 9 * * * *"
+	if isDebugMode() {
 		cronValue = "0 * * * * *"
 	}
 
@@ -41,7 +59,7 @@ func (b *Config) defineDomainCronJobs() {
 			if len(domains) != 0 {
 				for _, d := range domains {
 					// check if user already notified
-					notified, err := b.isNotified(d.UserId, cacheNamespaceDomainNotified)
+					notified, err := b.isNotified(d.FQDN, cacheNamespaceDomainNotified)
 					if err != nil {
 						log.Err(err).Msg("Error getting notified flag")
 					}
@@ -51,25 +69,27 @@ func (b *Config) defineDomainCronJobs() {
 					// send to channel
 					_, _, err = client.PostMessage(
 						b.ChannelName,
-						slack.MsgOptionText(fmt.Sprintf("<@%s>", d.UserId)+fmt.Sprintf("Your domain %s scheduled to delete at %s.", d.FQDN, d.DeleteAt.In(b.CmdHandler.Timezone).Format(messageTimeFormat)), false),
+						slack.MsgOptionText(mentionAll(d.Owners())+fmt.Sprintf(" Your domain %s scheduled to delete at %s.", d.FQDN, d.DeleteAt.In(b.CmdHandler.Timezone).Format(messageTimeFormat)), false),
 						slack.MsgOptionAsUser(true),
 					)
 					if err != nil {
 						log.Error().Err(err).Msgf("ID: %s, domain: %s", d.UserId, d.FQDN)
 					}
-					// send to user
-					_, _, err = client.PostMessage(
-						d.UserId,
-						slack.MsgOptionText(fmt.Sprintf("Your domain %s scheduled to delete at %s.", d.FQDN, d.DeleteAt.In(b.CmdHandler.Timezone).Format(messageTimeFormat)), false),
-						slack.MsgOptionAsUser(true),
-					)
-					if err != nil {
-						log.Error().Err(err).Msgf("ID: %s, domain: %s", d.UserId, d.FQDN)
+					// send to owners
+					for _, owner := range d.Owners() {
+						_, _, err = client.PostMessage(
+							owner,
+							slack.MsgOptionText(fmt.Sprintf("Your domain %s scheduled to delete at %s.", d.FQDN, d.DeleteAt.In(b.CmdHandler.Timezone).Format(messageTimeFormat)), false),
+							slack.MsgOptionAsUser(true),
+						)
+						if err != nil {
+							log.Error().Err(err).Msgf("ID: %s, domain: %s", owner, d.FQDN)
+						}
 					}
 					// set notified flag
-					err = b.setNotified(d.UserId, cacheNamespaceDomainNotified)
+					err = b.setNotified(d.FQDN, cacheNamespaceDomainNotified)
 					if err != nil {
-						log.Error().Err(err).Msgf("Failed to set notified flag", d.UserId, d.FQDN)
+						log.Error().Err(err).Msgf("Failed to set notified flag. ID: %s, domain: %s", d.UserId, d.FQDN)
 					}
 				}
 			}
@@ -107,25 +127,138 @@ func (b *Config) defineDomainCronJobs() {
 				for _, d := range domains {
 					_, _, err := client.PostMessage(
 						b.ChannelName,
-						slack.MsgOptionText(fmt.Sprintf("<@%s>", d.UserId)+fmt.Sprintf("Your domain %s deleted.", d.FQDN), false),
+						slack.MsgOptionText(mentionAll(d.Owners())+fmt.Sprintf(" Your domain %s deleted.", d.FQDN), false),
 						slack.MsgOptionAsUser(true),
 					)
 					if err != nil {
 						log.Error().Err(err).Msgf("ID: %s, domain: %s", d.UserId, d.FQDN)
 					}
-					// send to user
+					// send to owners
+					for _, owner := range d.Owners() {
+						_, _, err = client.PostMessage(
+							owner,
+							slack.MsgOptionText(fmt.Sprintf("Your domain %s deleted.", d.FQDN), false),
+							slack.MsgOptionAsUser(true),
+						)
+						if err != nil {
+							log.Error().Err(err).Msgf("ID: %s, domain: %s", owner, d.FQDN)
+						}
+					}
+					b.clearDomainFlags(d)
+				}
+			}
+		},
+	})
+}
+
+func (b *Config) defineDomainHealthCronJobs() {
+	cronValue := "0 */5 * * * *"
+	if isDebugMode() {
+		cronValue = "0 * * * * *"
+	}
+
+	b.bot.Job(cronValue, &slacker.JobDefinition{
+		Description: "Notification about unreachable domain upstreams",
+		Handler: func(jobCtx slacker.JobContext) {
+			client := jobCtx.APIClient()
+			domains, err := b.CmdHandler.DomainCheckHealth()
+			if err != nil {
+				log.Err(err).Msg("Error checking domains health")
+				return
+			}
+			for _, d := range domains {
+				// owners are notified once when the upstream goes down and once when it recovers
+				notified, err := b.isNotified(d.FQDN, cacheNamespaceDomainDown)
+				if err != nil {
+					log.Err(err).Msg("Error getting upstream down flag")
+					continue
+				}
+				var message string
+				switch {
+				case d.Health.Status == entities.HealthDown && !notified:
+					message = fmt.Sprintf("Upstream %s of your domain %s is unreachable: %s.", handlers.UpstreamAddress(d), d.FQDN, d.Health.Error)
+					err = b.setNotified(d.FQDN, cacheNamespaceDomainDown)
+				case d.Health.Status == entities.HealthUp && notified:
+					message = fmt.Sprintf("Upstream %s of your domain %s is reachable again.", handlers.UpstreamAddress(d), d.FQDN)
+					err = b.clearNotified(d.FQDN, cacheNamespaceDomainDown)
+				default:
+					continue
+				}
+				if err != nil {
+					log.Error().Err(err).Msgf("Failed to update upstream down flag. ID: %s, domain: %s", d.UserId, d.FQDN)
+					continue
+				}
+				for _, owner := range d.Owners() {
 					_, _, err = client.PostMessage(
-						d.UserId,
-						slack.MsgOptionText(fmt.Sprintf("Your domain %s deleted.", d.FQDN), false),
+						owner,
+						slack.MsgOptionText(message, false),
 						slack.MsgOptionAsUser(true),
 					)
 					if err != nil {
-						log.Error().Err(err).Msgf("ID: %s, domain: %s", d.UserId, d.FQDN)
+						log.Error().Err(err).Msgf("ID: %s, domain: %s", owner, d.FQDN)
 					}
-					// delete notified flag
-					err = b.clearNotified(d.UserId, cacheNamespaceDomainNotified)
+				}
+			}
+		},
+	})
+}
+
+func (b *Config) defineDomainLogsCronJobs() {
+	cronValue := "0 */10 * * * *"
+	b.bot.Job(cronValue, &slacker.JobDefinition{
+		Description: "Rotation of domain logs",
+		Handler: func(jobCtx slacker.JobContext) {
+			if err := b.CmdHandler.RotateLogs(); err != nil {
+				log.Err(err).Msg("Error rotating domain logs")
+			}
+		},
+	})
+}
+
+func (b *Config) defineDomainVpnCronJobs() {
+	cronValue := "0 */1 * * * *"
+	b.bot.Job(cronValue, &slacker.JobDefinition{
+		Description: "Following VPN addresses of domain owners",
+		Handler: func(jobCtx slacker.JobContext) {
+			client := jobCtx.APIClient()
+			domains, err := b.CmdHandler.DomainsFollowingVpn()
+			if err != nil {
+				log.Err(err).Msg("Error getting domains following vpn")
+				return
+			}
+			if len(domains) == 0 {
+				return
+			}
+			addresses, err := b.CmdHandler.VpnAddresses()
+			if err != nil {
+				log.Err(err).Msg("Error getting vpn addresses")
+				return
+			}
+			for _, d := range domains {
+				address, previous, err := b.syncVpnIp(addresses, b.emails.get(client, d.UserId), d)
+				if err != nil {
+					log.Error().Err(err).Msgf("Failed to follow vpn address. ID: %s, domain: %s", d.UserId, d.FQDN)
+					continue
+				}
+				if previous == "" {
+					continue
+				}
+				warning := b.CmdHandler.IpConflictWarning(d.UserId, d)
+				for _, owner := range d.Owners() {
+					message := fmt.Sprintf("Your VPN address has changed, domain %s now points to %s instead of %s.", d.FQDN, address, previous)
+					if owner != d.UserId {
+						message = fmt.Sprintf("VPN address of <@%s> has changed, domain %s now points to %s instead of %s.", d.UserId, d.FQDN, address, previous)
+					}
+					if warning != "" {
+						message += "\n" + warning
+					}
+					_, _, err = client.PostMessage(
+						owner,
+						slack.MsgOptionText(message, false),
+						slack.MsgOptionAsUser(true),
+					)
 					if err != nil {
-						log.Error().Err(err).Msgf("Failed to clear notified flag", d.UserId, d.FQDN)
+						log.Error().Err(err).Msgf("ID: %s, domain: %s", owner, d.FQDN)
 					}
 				}
 			}
@@ -133,6 +266,40 @@ func (b *Config) defineDomainCronJobs() {
 	})
 }
 
+// followVpnNow points the domain to the VPN address of the owner right after the mode is enabled
+// and describes the result.
+func (b *Config) followVpnNow(client *slack.Client, d *entities.Domain) string {
+	addresses, err := b.CmdHandler.VpnAddresses()
+	if err != nil {
+		log.Err(err).Msg("Error getting vpn addresses")
+		return "It will follow the VPN address of the owner, but the VPN server is unavailable now."
+	}
+	address, _, err := b.syncVpnIp(addresses, getUserEmail(client, d.UserId), d)
+	switch {
+	case err != nil:
+		log.Error().Err(err).Msgf("Failed to follow vpn address. ID: %s, domain: %s", d.UserId, d.FQDN)
+		return fmt.Sprintf("It will follow the VPN address of the owner, but the current one can't be used: %v", err)
+	case address == "":
+		return "It will follow the VPN address of the owner once they connect to the VPN."
+	}
+	message := fmt.Sprintf("It follows the VPN address of the owner, now %s.", address)
+	if warning := b.CmdHandler.IpConflictWarning(d.UserId, d); warning != "" {
+		message += "\n" + warning
+	}
+	return message
+}
+
+// syncVpnIp points the domain to the VPN address of the owner with the email. It returns the address,
+// empty if the owner is not connected, and the previous IP if it has changed.
+func (b *Config) syncVpnIp(addresses map[string]string, email string, d *entities.Domain) (address, previous string, err error) {
+	address, ok := addresses[email]
+	if email == "" || !ok {
+		return "", "", nil
+	}
+	previous, err = b.CmdHandler.DomainSyncVpnIp(d, address)
+	return address, previous, err
+}
+
 func (b *Config) defineVpnEUCronJobs() {
 	cronValue := "0 */1 * * * *"
 	b.bot.Job(cronValue, &slacker.JobDefinition{
@@ -166,7 +333,7 @@ func (b *Config) defineVpnEUCronJobs() {
 					// set notified flag
 					err = b.setNotified(a.UserEmail, cacheNamespaceVpnEUNotified)
 					if err != nil {
-						log.Error().Err(err).Msgf("Failed to set notified flag", a.UserEmail)
+						log.Error().Err(err).Msgf("Failed to set notified flag. ID: %s", a.UserEmail)
 					}
 				}
 			}
@@ -222,7 +389,7 @@ func (b *Config) defineVpnEUCronJobs() {
 					// delete notified flag
 					err = b.clearNotified(a.UserEmail, cacheNamespaceVpnEUNotified)
 					if err != nil {
-						log.Error().Err(err).Msgf("Failed to clear notified flag", a.UserEmail)
+						log.Error().Err(err).Msgf("Failed to clear notified flag. ID: %s", a.UserEmail)
 					}
 				}
 			}
@@ -1,7 +1,10 @@
 package bot
 
 import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
 	"github.com/rs/zerolog/log"
+	"github.com/shomali11/slacker"
 	"github.com/slack-go/slack"
 	"regexp"
 	"strings"
@@ -26,6 +29,125 @@ func extractEmail(text string) string {
 	return email
 }
 
+// reply sends a message to the command thread and logs if it fails
+func reply(botCtx slacker.BotContext, response slacker.ResponseWriter, message string) {
+	err := response.Reply(message, slacker.WithThreadReply(true))
+	if err != nil {
+		log.Err(err).Msgf("Error sending reply. Request: %v, user: %v", botCtx.Event().Text, botCtx.Event().UserID)
+	}
+}
+
+// commandArgs splits command parameters into separate words. Slack turns host names
+// into links like <http://host|host>, such links are replaced with the plain host.
+func commandArgs(params ...string) []string {
+	var args []string
+	for _, p := range params {
+		for _, arg := range strings.Fields(p) {
+			args = append(args, cleanSlackLink(arg))
+		}
+	}
+	return args
+}
+
+// extractUserId returns the user ID from a Slack mention like <@U0123456789|name>.
+// The closing bracket may already be stripped by the event text sanitizer.
+func extractUserId(mention string) (string, bool) {
+	if !strings.HasPrefix(mention, "<@") {
+		return "", false
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(mention, "<@"), ">")
+	if i := strings.Index(id, "|"); i != -1 {
+		id = id[:i]
+	}
+	return id, id != ""
+}
+
+// mentionAll returns mentions of all the users separated by spaces
+func mentionAll(userIds []string) string {
+	var mentions []string
+	for _, id := range userIds {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+	}
+	return strings.Join(mentions, " ")
+}
+
+// popKeyword returns the word that follows the keyword and the rest of the arguments
+// without both of them.
+func popKeyword(args []string, keyword string) (string, []string) {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == keyword {
+			value := args[i+1]
+			rest := append(append([]string{}, args[:i]...), args[i+2:]...)
+			return value, rest
+		}
+	}
+	return "", args
+}
+
+// actingUser returns the user the command is run for and the arguments without the
+// `--as @user` option. Only admins can act on behalf of other users.
+func (b *Config) actingUser(userId string, args []string) (string, []string, error) {
+	mention, rest := popKeyword(args, "--as")
+	if mention == "" {
+		// Slack clients may replace the double dash with an em dash
+		mention, rest = popKeyword(args, "—as")
+	}
+	if mention == "" {
+		return userId, args, nil
+	}
+	if !contains(b.AdminUserIDs, userId) {
+		return "", nil, fmt.Errorf("only admins can act on behalf of other users")
+	}
+	targetId, ok := extractUserId(mention)
+	if !ok {
+		return "", nil, fmt.Errorf("expected `--as @user`")
+	}
+	return targetId, rest, nil
+}
+
+// auditAdminAction posts the action of an admin on someone else's domain to the channel
+// and explains it to the affected users in private messages.
+func (b *Config) auditAdminAction(client *slack.Client, adminId string, affected []string, action string) {
+	message := fmt.Sprintf("Admin <@%s> %s.", adminId, action)
+	_, _, err := client.PostMessage(b.ChannelName, slack.MsgOptionText(message, false), slack.MsgOptionAsUser(true))
+	if err != nil {
+		log.Err(err).Msgf("Error posting audit message. Admin: %s, action: %s", adminId, action)
+	}
+	for _, userId := range affected {
+		_, _, err = client.PostMessage(userId, slack.MsgOptionText(message, false))
+		if err != nil {
+			log.Err(err).Msgf("Error sending direct message. ID: %s, action: %s", userId, action)
+		}
+	}
+}
+
+// slackLinkText returns the link as the user typed it: the text of <http://host|host>
+// or the URL of <https://host>. Other words are returned as is.
+func slackLinkText(s string) string {
+	if !strings.HasPrefix(s, "<http://") && !strings.HasPrefix(s, "<https://") {
+		return s
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "<"), ">")
+	if i := strings.Index(s, "|"); i != -1 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// cleanSlackLink returns the text of a Slack link. The closing bracket may already be
+// stripped by the event text sanitizer.
+func cleanSlackLink(s string) string {
+	if !strings.HasPrefix(s, "<http://") && !strings.HasPrefix(s, "<https://") {
+		return s
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "<"), ">")
+	if i := strings.Index(s, "|"); i != -1 {
+		return s[i+1:]
+	}
+	s = strings.TrimPrefix(s, "http://")
+	return strings.TrimPrefix(s, "https://")
+}
+
 // setNotified sets the notified flag for a user in the local cache
 func (b *Config) setNotified(userId, namespace string) error {
 	return b.Cache.Set(namespace, userId, "true")
@@ -41,6 +163,17 @@ func (b *Config) clearNotified(userId, namespace string) error {
 	return b.Cache.Delete(namespace, userId)
 }
 
+// clearDomainFlags clears expiry and upstream down flags of a deleted domain, so a new
+// domain with the same FQDN starts clean.
+func (b *Config) clearDomainFlags(d *entities.Domain) {
+	if err := b.clearNotified(d.FQDN, cacheNamespaceDomainNotified); err != nil {
+		log.Error().Err(err).Msgf("Failed to clear notified flag. ID: %s, domain: %s", d.UserId, d.FQDN)
+	}
+	if err := b.clearNotified(d.FQDN, cacheNamespaceDomainDown); err != nil {
+		log.Error().Err(err).Msgf("Failed to clear upstream down flag. ID: %s, domain: %s", d.UserId, d.FQDN)
+	}
+}
+
 // sendVpnWelcomeMessage sends a welcome message if it is set to a user when creating new config
 func sendVPNWelcomeMessage(client *slack.Client, userId, message string) error {
 	if message == "" {
@@ -54,6 +187,38 @@ func sendVPNWelcomeMessage(client *slack.Client, userId, message string) error {
 	return nil
 }
 
+// sendBasicAuthCredentials sends newly generated basic auth credentials to the domain owners
+func (b *Config) sendBasicAuthCredentials(client *slack.Client, d *entities.Domain) {
+	if d.BasicAuthPassword == "" {
+		return
+	}
+	message := fmt.Sprintf("Basic auth credentials for %s:\nUser: `%s`\nPassword: `%s`", d.FQDN, d.BasicAuthUser, d.BasicAuthPassword)
+	for _, owner := range d.Owners() {
+		_, _, err := client.PostMessage(owner, slack.MsgOptionText(message, false))
+		if err != nil {
+			log.Err(err).Msgf("Error sending basic auth credentials. ID: %s, domain: %s", owner, d.FQDN)
+		}
+	}
+}
+
+// uploadFile uploads the content as a file to the thread of the command message.
+func uploadFile(botCtx slacker.BotContext, filename, title, snippetType, content string) error {
+	threadTs := botCtx.Event().ThreadTimeStamp
+	if threadTs == "" {
+		threadTs = botCtx.Event().TimeStamp
+	}
+	_, err := botCtx.APIClient().UploadFile(slack.UploadFileParameters{
+		Content:         content,
+		FileSize:        len(content),
+		Filename:        filename,
+		Title:           title,
+		SnippetType:     snippetType,
+		Channel:         botCtx.Event().ChannelID,
+		ThreadTimestamp: threadTs,
+	})
+	return err
+}
+
 // getSlackUserIdByEmail gets the Slack user ID for a given email address
 func getSlackUserIdByEmail(client *slack.Client, email string) (string, error) {
 	users, err := client.GetUsers()
@@ -5,6 +5,7 @@ import (
 	"github.com/1k-off/dev-helper-bot/internal/webserver"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
+	"net"
 	"time"
 )
 
@@ -35,12 +36,41 @@ type Pritunl struct {
 	OrganizationEU string `mapstructure:"organization_eu"`
 }
 
+// Policies for domains pointing to the same upstream as domains of other users.
+const (
+	IpConflictsWarn   = "warn"
+	IpConflictsRefuse = "refuse"
+)
+
 type Webserver struct {
-	ParentDomain   string              `mapstructure:"parent_domain"`
-	AllowedSubnets []string            `mapstructure:"allowed_subnets"`
-	DeniedIPs      []string            `mapstructure:"denied_ips"`
-	Kind           string              `mapstructure:"kind"`
-	Service        webserver.Webserver `mapstructure:"-"`
+	ParentDomain        string                      `mapstructure:"parent_domain"`
+	AllowedSubnets      []string                    `mapstructure:"allowed_subnets"`
+	DeniedIPs           []string                    `mapstructure:"denied_ips"`
+	OfficeNetworks      []string                    `mapstructure:"office_networks"`
+	VpnNetworks         []string                    `mapstructure:"vpn_networks"`
+	Kind                string                      `mapstructure:"kind"`
+	Lifetime            DomainLifetime              `mapstructure:"lifetime"`
+	ReservationCooldown string                      `mapstructure:"reservation_cooldown"`
+	Presets             map[string]webserver.Preset `mapstructure:"presets"`
+	Logs                DomainLogs                  `mapstructure:"logs"`
+	WildcardTls         bool                        `mapstructure:"wildcard_tls"`
+	AliasHosts          []string                    `mapstructure:"alias_hosts"`
+	IpVerification      IpVerification              `mapstructure:"ip_verification"`
+	IpConflicts         string                      `mapstructure:"ip_conflicts"`
+	Service             webserver.Webserver         `mapstructure:"-"`
+}
+
+// DomainLogs limits the size of per-domain access and error logs.
+type DomainLogs struct {
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+	Keep      int `mapstructure:"keep"`
+}
+
+// IpVerification makes users prove control over an IP before domains are mapped to it.
+type IpVerification struct {
+	Enabled       bool     `mapstructure:"enabled"`
+	ValidFor      string   `mapstructure:"valid_for"`
+	ExemptSubnets []string `mapstructure:"exempt_subnets"`
 }
 
 type Slack struct {
@@ -55,6 +85,21 @@ func newDefaultConfig() *Config {
 			LogLevel: "info",
 			Timezone: "Europe/Kyiv",
 		},
+		Webserver: Webserver{
+			Lifetime: DomainLifetime{
+				Default: "2w",
+				Max:     "4w",
+			},
+			ReservationCooldown: "2w",
+			Logs: DomainLogs{
+				MaxSizeMB: 10,
+				Keep:      3,
+			},
+			IpVerification: IpVerification{
+				ValidFor: "4w",
+			},
+			IpConflicts: IpConflictsWarn,
+		},
 	}
 }
 
@@ -82,7 +127,13 @@ func Load(path string) (*Config, error) {
 		tz, _ = time.LoadLocation("Europe/Kyiv")
 	}
 	cfg.Timezone = tz
-	cfg.Webserver.Service = webserver.New(cfg.Webserver.Kind)
+	cfg.Webserver.Service = webserver.New(cfg.Webserver.Kind, webserver.Settings{
+		OfficeNetworks: cfg.Webserver.OfficeNetworks,
+		VpnNetworks:    cfg.Webserver.VpnNetworks,
+		Presets:        cfg.Webserver.Presets,
+		LogMaxSizeMB:   cfg.Webserver.Logs.MaxSizeMB,
+		LogKeep:        cfg.Webserver.Logs.Keep,
+	})
 	return cfg, nil
 }
 
@@ -95,6 +146,49 @@ func (c *Config) Validate() error {
 		log.Debug().Msgf("failed to validate server kind: %s", err)
 		return err
 	}
+	if err := c.Webserver.Lifetime.validate(); err != nil {
+		log.Debug().Msgf("failed to validate domain lifetime: %s", err)
+		return err
+	}
+	if _, err := ParseDuration(c.Webserver.ReservationCooldown); c.Webserver.ReservationCooldown != "0" && err != nil {
+		log.Debug().Msgf("failed to validate reservation cooldown: %s", err)
+		return err
+	}
+	for name, p := range c.Webserver.Presets {
+		if err := p.Validate(); err != nil {
+			log.Debug().Msgf("failed to validate preset %s: %s", name, err)
+			return fmt.Errorf("invalid preset %s: %w", name, err)
+		}
+	}
+	if c.Webserver.Logs.MaxSizeMB < 1 || c.Webserver.Logs.Keep < 0 {
+		log.Debug().Msgf("failed to validate domain logs: max size %d, keep %d", c.Webserver.Logs.MaxSizeMB, c.Webserver.Logs.Keep)
+		return fmt.Errorf("invalid domain logs settings: max_size_mb must be positive and keep can't be negative")
+	}
+	if err := validateNetworks(append(append([]string{}, c.Webserver.OfficeNetworks...), c.Webserver.VpnNetworks...)); err != nil {
+		log.Debug().Msgf("failed to validate networks: %s", err)
+		return err
+	}
+	if _, err := ParseDuration(c.Webserver.IpVerification.ValidFor); err != nil {
+		log.Debug().Msgf("failed to validate ip verification lifetime: %s", err)
+		return err
+	}
+	if err := validateNetworks(c.Webserver.IpVerification.ExemptSubnets); err != nil {
+		log.Debug().Msgf("failed to validate ip verification exempt subnets: %s", err)
+		return err
+	}
+	if c.Webserver.IpConflicts != IpConflictsWarn && c.Webserver.IpConflicts != IpConflictsRefuse {
+		log.Debug().Msgf("failed to validate ip conflicts policy: %s", c.Webserver.IpConflicts)
+		return fmt.Errorf("invalid ip conflicts policy: %s", c.Webserver.IpConflicts)
+	}
+	return nil
+}
+
+func validateNetworks(networks []string) error {
+	for _, n := range networks {
+		if _, _, err := net.ParseCIDR(n); err != nil {
+			return fmt.Errorf("invalid network: %s", n)
+		}
+	}
 	return nil
 }
 
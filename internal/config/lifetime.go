@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const RoleAdmin = "admin"
+
+// DomainLifetime limits how long a domain lives before it is deleted.
+// Roles override the default values for users with the role.
+type DomainLifetime struct {
+	Default string                    `mapstructure:"default"`
+	Max     string                    `mapstructure:"max"`
+	Roles   map[string]DomainLifetime `mapstructure:"roles"`
+}
+
+// For returns the default and maximal lifetime for the role.
+// Values are validated on config load, so parse errors are ignored here.
+func (l DomainLifetime) For(role string) (def, max time.Duration) {
+	def, _ = ParseDuration(l.Default)
+	max, _ = ParseDuration(l.Max)
+	if r, ok := l.Roles[role]; ok {
+		if r.Default != "" {
+			def, _ = ParseDuration(r.Default)
+		}
+		if r.Max != "" {
+			max, _ = ParseDuration(r.Max)
+		}
+	}
+	return def, max
+}
+
+func (l DomainLifetime) validate() error {
+	def, max := l.For("")
+	if def <= 0 || max <= 0 {
+		return fmt.Errorf("invalid domain lifetime: default %q, max %q", l.Default, l.Max)
+	}
+	if def > max {
+		return fmt.Errorf("default domain lifetime %s is greater than max %s", l.Default, l.Max)
+	}
+	for role, r := range l.Roles {
+		for _, v := range []string{r.Default, r.Max} {
+			if _, err := ParseDuration(v); v != "" && err != nil {
+				return fmt.Errorf("invalid domain lifetime for role %s: %w", role, err)
+			}
+		}
+		if def, max = l.For(role); def > max {
+			return fmt.Errorf("default domain lifetime for role %s is greater than max", role)
+		}
+	}
+	return nil
+}
+
+// ParseDuration parses durations in days (5d) and weeks (2w) in addition to
+// the time.ParseDuration format. Only positive durations are valid.
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if !strings.HasSuffix(s, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(s, suffix))
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
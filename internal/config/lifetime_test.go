@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "hours", value: "12h", want: 12 * time.Hour},
+		{name: "minutes", value: "90m", want: 90 * time.Minute},
+		{name: "days", value: "5d", want: 5 * 24 * time.Hour},
+		{name: "weeks", value: "2w", want: 14 * 24 * time.Hour},
+		{name: "empty", value: "", wantErr: true},
+		{name: "no unit", value: "5", wantErr: true},
+		{name: "zero days", value: "0d", wantErr: true},
+		{name: "negative days", value: "-1d", wantErr: true},
+		{name: "negative hours", value: "-5h", wantErr: true},
+		{name: "fractional weeks", value: "1.5w", wantErr: true},
+		{name: "combined days", value: "1d12h", wantErr: true},
+		{name: "unknown unit", value: "3y", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuration(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainLifetimeFor(t *testing.T) {
+	l := DomainLifetime{
+		Default: "7d",
+		Max:     "2w",
+		Roles:   map[string]DomainLifetime{RoleAdmin: {Max: "30d"}},
+	}
+	day := 24 * time.Hour
+	if def, max := l.For(""); def != 7*day || max != 14*day {
+		t.Errorf("For(\"\") = %s, %s, want %s, %s", def, max, 7*day, 14*day)
+	}
+	if def, max := l.For(RoleAdmin); def != 7*day || max != 30*day {
+		t.Errorf("For(%q) = %s, %s, want %s, %s", RoleAdmin, def, max, 7*day, 30*day)
+	}
+}
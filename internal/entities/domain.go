@@ -1,16 +1,126 @@
 package entities
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
+// Domain is a developer domain. BasicAuthPassword is set only when new basic auth
+// credentials are generated and is never stored.
 type Domain struct {
-	Id        string    `bson:"_id,omitempty"`
-	FQDN      string    `bson:"fqdn"`
-	IP        string    `bson:"ip"`
-	UserId    string    `bson:"user_id"`
-	UserName  string    `bson:"user_name"`
-	CreatedAt time.Time `bson:"created_at"`
-	DeleteAt  time.Time `bson:"delete_at"`
-	BasicAuth bool      `bson:"basic_auth"`
-	FullSsl   bool      `bson:"full_ssl"`
-	Port      string    `bson:"port"`
+	Id                string    `bson:"_id,omitempty"`
+	Name              string    `bson:"name"`
+	FQDN              string    `bson:"fqdn"`
+	IP                string    `bson:"ip"`
+	UserId            string    `bson:"user_id"`
+	UserName          string    `bson:"user_name"`
+	CreatedAt         time.Time `bson:"created_at"`
+	DeleteAt          time.Time `bson:"delete_at"`
+	BasicAuth         bool      `bson:"basic_auth"`
+	BasicAuthUser     string    `bson:"basic_auth_user,omitempty"`
+	BasicAuthHash     string    `bson:"basic_auth_hash,omitempty"`
+	BasicAuthPassword string    `bson:"-"`
+	FullSsl           bool      `bson:"full_ssl"`
+	Port              string    `bson:"port"`
+	Routes            []Route   `bson:"routes,omitempty"`
+	CoOwners          []string  `bson:"co_owners,omitempty"`
+	Access            Access    `bson:"access"`
+	Health            Health    `bson:"health"`
+	State             string    `bson:"state,omitempty"`
+	Preset            string    `bson:"preset,omitempty"`
+	Proxy             Proxy     `bson:"proxy"`
+	Headers           []Header  `bson:"headers,omitempty"`
+	Logs              bool      `bson:"logs"`
+	Wildcard          bool      `bson:"wildcard"`
+	Aliases           []string  `bson:"aliases,omitempty"`
+	IpMode            string    `bson:"ip_mode,omitempty"`
+}
+
+// String hides basic auth secrets from logs.
+func (d *Domain) String() string {
+	type domain Domain
+	c := domain(*d)
+	c.BasicAuthHash, c.BasicAuthPassword = "", ""
+	return fmt.Sprintf("%v", &c)
+}
+
+// IsPaused reports whether the domain serves the holding page instead of proxying.
+func (d *Domain) IsPaused() bool {
+	return d.State == StatePaused
+}
+
+// Owners returns the owner and co-owners of the domain.
+func (d *Domain) Owners() []string {
+	return append([]string{d.UserId}, d.CoOwners...)
+}
+
+// IsOwner reports whether the user is the owner or a co-owner of the domain.
+func (d *Domain) IsOwner(userId string) bool {
+	for _, id := range d.Owners() {
+		if id == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// Route proxies requests with the path prefix to a separate upstream.
+type Route struct {
+	Path string `bson:"path"`
+	IP   string `bson:"ip"`
+	Port string `bson:"port"`
+}
+
+// Access is the viewer access policy of the domain.
+type Access struct {
+	// AllowedCidrs limits source networks that can open the domain
+	AllowedCidrs []string `bson:"allowed_cidrs,omitempty"`
+	// OfficeBypass lets office networks open the domain without basic auth
+	OfficeBypass bool `bson:"office_bypass"`
+	// VpnOnly limits viewers to VPN networks
+	VpnOnly bool `bson:"vpn_only"`
+}
+
+const (
+	HeaderRequest  = "request"
+	HeaderResponse = "response"
+)
+
+// Header is a request header sent to the upstream or a response header sent to the viewer.
+type Header struct {
+	Kind  string `bson:"kind"`
+	Name  string `bson:"name"`
+	Value string `bson:"value"`
+}
+
+// Proxy tunes proxying to the upstream. Empty values mean webserver defaults.
+type Proxy struct {
+	Websocket bool `bson:"websocket"`
+	// Protocol is h2c or grpc, http and https are selected by Domain.FullSsl
+	Protocol string `bson:"protocol,omitempty"`
+	// Timeout is the read and send timeout like 5m
+	Timeout string `bson:"timeout,omitempty"`
+	// MaxBody is the max request body size like 10m
+	MaxBody string `bson:"max_body,omitempty"`
+}
+
+// IpModeVpn makes the domain IP follow the VPN address of the owner.
+const IpModeVpn = "vpn"
+
+const (
+	StateActive = "active"
+	StatePaused = "paused"
+)
+
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// Health is the result of the last upstream health check.
+type Health struct {
+	Status    string        `bson:"status"`
+	Latency   time.Duration `bson:"latency"`
+	Error     string        `bson:"error,omitempty"`
+	CheckedAt time.Time     `bson:"checked_at"`
 }
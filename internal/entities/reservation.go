@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// Reservation keeps the name of a deleted domain for its previous owner until ExpiresAt.
+type Reservation struct {
+	Id        string    `bson:"_id,omitempty"`
+	FQDN      string    `bson:"fqdn"`
+	UserId    string    `bson:"user_id"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// Revision is a saved state of a domain after a change, numbered from 1 for each domain.
+type Revision struct {
+	Id        string    `bson:"_id,omitempty"`
+	FQDN      string    `bson:"fqdn"`
+	Rev       int       `bson:"rev"`
+	Actor     string    `bson:"actor"`
+	Action    string    `bson:"action"`
+	Domain    Domain    `bson:"domain"`
+	Config    string    `bson:"config"`
+	CreatedAt time.Time `bson:"created_at"`
+}
@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// IpVerification is a token the user serves from the IP to prove control over it.
+// Verified records let the user map domains to the IP until ExpiresAt.
+type IpVerification struct {
+	Id        string    `bson:"_id,omitempty"`
+	UserId    string    `bson:"user_id"`
+	IP        string    `bson:"ip"`
+	Token     string    `bson:"token"`
+	Verified  bool      `bson:"verified"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/rs/zerolog/log"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const maxAllowedCidrs = 20
+
+var domainAccessActions = []string{"allow", "disallow", "office-bypass", "vpn-only", "show", "reset"}
+
+// IsDomainAccessAction reports whether the value is a domain access action.
+func IsDomainAccessAction(value string) bool {
+	for _, a := range domainAccessActions {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
+
+// DomainAccess changes the viewer access policy of the domain and returns the resulting policy.
+func (h *Handler) DomainAccess(userId, selector, action, value string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	if action == "show" {
+		return h.describeAccess(d), nil
+	}
+
+	access := d.Access
+	access.AllowedCidrs = append([]string{}, d.Access.AllowedCidrs...)
+	switch action {
+	case "allow", "disallow":
+		cidr, err := normalizeCidr(value)
+		if err != nil {
+			return "", err
+		}
+		if action == "allow" {
+			access.AllowedCidrs, err = addCidr(access.AllowedCidrs, cidr)
+		} else {
+			access.AllowedCidrs, err = removeCidr(access.AllowedCidrs, cidr)
+		}
+		if err != nil {
+			return "", err
+		}
+	case "office-bypass", "vpn-only":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("value must be true or false")
+		}
+		if action == "office-bypass" {
+			access.OfficeBypass = enabled
+		} else {
+			access.VpnOnly = enabled
+		}
+	case "reset":
+		access = entities.Access{}
+	default:
+		return "", fmt.Errorf("unknown access action %q", action)
+	}
+	if err = h.validateAccess(access); err != nil {
+		return "", err
+	}
+
+	d.Access = access
+	if err = h.updateNginxConf(d); err != nil {
+		return "", err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return "", err
+	}
+	h.recordRevision(d, userId, fmt.Sprintf("access %s %s", action, value))
+	log.Info().Msg(fmt.Sprintf("[bot] changed access policy of domain %s: %s %s", d.FQDN, action, value))
+	return h.describeAccess(d), nil
+}
+
+func (h *Handler) validateAccess(a entities.Access) error {
+	if a.OfficeBypass && len(h.Webserver.OfficeNetworks) == 0 {
+		return fmt.Errorf("office networks are not configured")
+	}
+	if a.VpnOnly && len(h.Webserver.VpnNetworks) == 0 {
+		return fmt.Errorf("vpn networks are not configured")
+	}
+	if a.OfficeBypass && (a.VpnOnly || len(a.AllowedCidrs) > 0) {
+		return fmt.Errorf("office bypass can't be combined with an allowlist or vpn-only access")
+	}
+	return nil
+}
+
+// describeAccess returns a human-readable access policy of the domain.
+func (h *Handler) describeAccess(d *entities.Domain) string {
+	var lines []string
+	if len(d.Access.AllowedCidrs) > 0 {
+		lines = append(lines, "Allowed networks: "+strings.Join(d.Access.AllowedCidrs, ", "))
+	}
+	if d.Access.VpnOnly {
+		lines = append(lines, "VPN only: "+strings.Join(h.Webserver.VpnNetworks, ", "))
+	}
+	if d.Access.OfficeBypass {
+		note := ""
+		if !d.BasicAuth {
+			note = " (has no effect while basic auth is off)"
+		}
+		lines = append(lines, "Office networks skip basic auth"+note)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "Open to everyone")
+	}
+	return fmt.Sprintf("Access policy of %s:\n%s", d.FQDN, strings.Join(lines, "\n"))
+}
+
+// normalizeCidr accepts a network or a single IP and returns the network in canonical form.
+func normalizeCidr(value string) (string, error) {
+	if ip := net.ParseIP(value); ip != nil {
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid network %q, expected <ip> or <ip>/<mask>", value)
+	}
+	return network.String(), nil
+}
+
+func addCidr(cidrs []string, cidr string) ([]string, error) {
+	for _, c := range cidrs {
+		if c == cidr {
+			return nil, fmt.Errorf("network %s is already allowed", cidr)
+		}
+	}
+	if len(cidrs) >= maxAllowedCidrs {
+		return nil, fmt.Errorf("domain can't have more than %d allowed networks", maxAllowedCidrs)
+	}
+	return append(cidrs, cidr), nil
+}
+
+func removeCidr(cidrs []string, cidr string) ([]string, error) {
+	var result []string
+	for _, c := range cidrs {
+		if c != cidr {
+			result = append(result, c)
+		}
+	}
+	if len(result) == len(cidrs) {
+		return nil, fmt.Errorf("network %s is not in the allowlist", cidr)
+	}
+	return result, nil
+}
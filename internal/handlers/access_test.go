@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"github.com/1k-off/dev-helper-bot/internal/config"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"testing"
+)
+
+func TestNormalizeCidr(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "ipv4 address", value: "1.2.3.4", want: "1.2.3.4/32"},
+		{name: "ipv6 address", value: "fd00::1", want: "fd00::1/128"},
+		{name: "ipv6 address in long form", value: "fd00:0:0:0:0:0:0:1", want: "fd00::1/128"},
+		{name: "ipv4 network", value: "10.8.0.0/16", want: "10.8.0.0/16"},
+		{name: "host bits are cleared", value: "10.8.1.2/16", want: "10.8.0.0/16"},
+		{name: "ipv6 network", value: "fd00::1/64", want: "fd00::/64"},
+		{name: "empty", value: "", wantErr: true},
+		{name: "hostname", value: "office.example.com", wantErr: true},
+		{name: "mask out of range", value: "10.8.0.0/33", wantErr: true},
+		{name: "config syntax", value: "10.8.0.0/16;", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeCidr(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeCidr(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeCidr(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAccess(t *testing.T) {
+	configured := &Handler{Webserver: config.Webserver{
+		OfficeNetworks: []string{"1.2.3.0/24"},
+		VpnNetworks:    []string{"10.8.0.0/16"},
+	}}
+	tests := []struct {
+		name    string
+		h       *Handler
+		access  entities.Access
+		wantErr bool
+	}{
+		{name: "open", h: configured, access: entities.Access{}},
+		{name: "allowlist", h: configured, access: entities.Access{AllowedCidrs: []string{"1.2.3.4/32"}}},
+		{name: "vpn only", h: configured, access: entities.Access{VpnOnly: true}},
+		{name: "vpn only with allowlist", h: configured, access: entities.Access{VpnOnly: true, AllowedCidrs: []string{"1.2.3.4/32"}}},
+		{name: "office bypass", h: configured, access: entities.Access{OfficeBypass: true}},
+		{name: "office bypass with allowlist", h: configured, access: entities.Access{OfficeBypass: true, AllowedCidrs: []string{"1.2.3.4/32"}}, wantErr: true},
+		{name: "office bypass with vpn only", h: configured, access: entities.Access{OfficeBypass: true, VpnOnly: true}, wantErr: true},
+		{name: "office networks are not configured", h: &Handler{}, access: entities.Access{OfficeBypass: true}, wantErr: true},
+		{name: "vpn networks are not configured", h: &Handler{}, access: entities.Access{VpnOnly: true}, wantErr: true},
+		{name: "allowlist without configured networks", h: &Handler{}, access: entities.Access{AllowedCidrs: []string{"1.2.3.4/32"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.h.validateAccess(tt.access); (err != nil) != tt.wantErr {
+				t.Errorf("validateAccess(%+v) error = %v, wantErr %v", tt.access, err, tt.wantErr)
+			}
+		})
+	}
+}
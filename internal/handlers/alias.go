@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+const (
+	maxDomainAliases  = 10
+	maxHostnameLength = 253
+)
+
+// DomainAliasAdd adds a hostname alias to the domain. The alias is a label under the parent domain,
+// e.g. j-doe-auth, or an external hostname approved by admins in the config. Admins may add any hostname.
+func (h *Handler) DomainAliasAdd(userId, selector, alias string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	hostname, internal, err := h.aliasHostname(alias)
+	if err != nil {
+		return "", err
+	}
+	if len(d.Aliases) >= maxDomainAliases {
+		return "", fmt.Errorf("domain can't have more than %d aliases", maxDomainAliases)
+	}
+	if err = h.checkAliasAvailable(userId, hostname, internal); err != nil {
+		return "", err
+	}
+
+	d.Aliases = append(d.Aliases, hostname)
+	if err = h.updateNginxConf(d); err != nil {
+		return "", err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			// another domain took the alias after the check
+			d.Aliases = d.Aliases[:len(d.Aliases)-1]
+			h.revertNginxConf(d)
+			return "", fmt.Errorf("%w: %s", ErrDomainExists, hostname)
+		}
+		return "", err
+	}
+	if internal {
+		h.releaseOwnReservation(hostname)
+	}
+	h.recordRevision(d, userId, "alias add "+hostname)
+	log.Info().Msg(fmt.Sprintf("[bot] added alias %s to domain %s", hostname, d.FQDN))
+	return fmt.Sprintf("Alias %s added to %s", hostname, d.FQDN), nil
+}
+
+// DomainAliasRemove removes the alias from the domain.
+func (h *Handler) DomainAliasRemove(userId, selector, alias string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	hostname, _, err := h.aliasHostname(alias)
+	if err != nil {
+		return "", err
+	}
+
+	var aliases []string
+	for _, a := range d.Aliases {
+		if a != hostname {
+			aliases = append(aliases, a)
+		}
+	}
+	if len(aliases) == len(d.Aliases) {
+		return "", fmt.Errorf("%s is not an alias of %s", hostname, d.FQDN)
+	}
+	d.Aliases = aliases
+
+	if err = h.updateNginxConf(d); err != nil {
+		return "", err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return "", err
+	}
+	h.recordRevision(d, userId, "alias remove "+hostname)
+	log.Info().Msg(fmt.Sprintf("[bot] removed alias %s from domain %s", hostname, d.FQDN))
+	return fmt.Sprintf("Alias %s removed from %s", hostname, d.FQDN), nil
+}
+
+// DomainAliasList returns a human-readable list of the domain aliases.
+func (h *Handler) DomainAliasList(userId, selector string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	if len(d.Aliases) == 0 {
+		return fmt.Sprintf("Domain %s has no aliases", d.FQDN), nil
+	}
+	return fmt.Sprintf("Aliases of %s:\n%s", d.FQDN, strings.Join(d.Aliases, "\n")), nil
+}
+
+// aliasHostname returns the hostname of the alias and whether it is under the parent domain.
+// A single label means a hostname under the parent domain.
+func (h *Handler) aliasHostname(alias string) (hostname string, internal bool, err error) {
+	alias = strings.TrimSuffix(strings.ToLower(alias), ".")
+	if alias == h.Webserver.ParentDomain {
+		return "", false, ErrReservedName
+	}
+	label, underParent := strings.CutSuffix(alias, "."+h.Webserver.ParentDomain)
+	if !strings.Contains(alias, ".") {
+		label, underParent = alias, true
+	}
+	if underParent {
+		if strings.Contains(label, ".") {
+			return "", false, fmt.Errorf("aliases under %s must be a single label like j-doe-auth", h.Webserver.ParentDomain)
+		}
+		if err = validateLabel(label); err != nil {
+			return "", false, err
+		}
+		return label + "." + h.Webserver.ParentDomain, true, nil
+	}
+	if len(alias) > maxHostnameLength {
+		return "", false, fmt.Errorf("hostname %s is too long", alias)
+	}
+	for _, l := range strings.Split(alias, ".") {
+		if len(l) > maxLabelLength || !domainNameRegexp.MatchString(l) {
+			return "", false, fmt.Errorf("invalid hostname %s", alias)
+		}
+	}
+	return alias, false, nil
+}
+
+// checkAliasAvailable checks that the user may add the alias hostname and nobody uses or keeps it.
+func (h *Handler) checkAliasAvailable(userId, hostname string, internal bool) error {
+	if !internal && !h.IsAdmin(userId) && !h.aliasHostApproved(hostname) {
+		return fmt.Errorf("external hostname %s is not approved, ask an admin to add it to webserver.alias_hosts", hostname)
+	}
+	if err := h.checkFqdnFree(hostname); err != nil {
+		return err
+	}
+	if internal {
+		return h.checkFqdnReserved(userId, hostname)
+	}
+	return nil
+}
+
+// aliasHostApproved reports whether the external hostname matches webserver.alias_hosts,
+// which lists hostnames and suffixes like *.example.com.
+func (h *Handler) aliasHostApproved(hostname string) bool {
+	for _, approved := range h.Webserver.AliasHosts {
+		approved = strings.ToLower(approved)
+		if suffix, ok := strings.CutPrefix(approved, "*"); ok && strings.HasSuffix(hostname, suffix) {
+			return true
+		}
+		if approved == hostname {
+			return true
+		}
+	}
+	return false
+}
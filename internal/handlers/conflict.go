@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/config"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/rs/zerolog/log"
+	"net"
+	"strings"
+)
+
+// ipConflicts describes domains and routes of other users which proxy to the same IP and port.
+// The domain with the fqdn is skipped, so an updated domain doesn't conflict with itself.
+func (h *Handler) ipConflicts(userId, fqdn, ip, port string) ([]string, error) {
+	domains, err := h.Store.DomainRepository().GetAllByIp(ip)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []string
+	for _, d := range domains {
+		if d.FQDN == fqdn || d.IsOwner(userId) {
+			continue
+		}
+		if d.IP == ip && upstreamPort(d) == port {
+			conflicts = append(conflicts, fmt.Sprintf("%s of <@%s>", d.FQDN, d.UserId))
+		}
+		for _, r := range d.Routes {
+			if r.IP == ip && r.Port == port {
+				conflicts = append(conflicts, fmt.Sprintf("%s%s of <@%s>", d.FQDN, r.Path, d.UserId))
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// checkIpConflict refuses the upstream used by another user when the conflict policy is refuse.
+func (h *Handler) checkIpConflict(userId, fqdn, ip, port string) error {
+	if h.Webserver.IpConflicts != config.IpConflictsRefuse {
+		return nil
+	}
+	conflicts, err := h.ipConflicts(userId, fqdn, ip, port)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s is used by %s, check the IP or ask the owner", ErrIpConflict, net.JoinHostPort(ip, port), strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// IpConflictWarning returns a warning when the domain proxies to the same IP and port as domains
+// of other users, or an empty string. Errors are only logged, the domain is already saved.
+func (h *Handler) IpConflictWarning(userId string, d *entities.Domain) string {
+	if d.IP == "" {
+		return ""
+	}
+	return h.ipConflictWarning(userId, d.FQDN, d.IP, upstreamPort(d))
+}
+
+func (h *Handler) ipConflictWarning(userId, fqdn, ip, port string) string {
+	if h.Webserver.IpConflicts != config.IpConflictsWarn {
+		return ""
+	}
+	conflicts, err := h.ipConflicts(userId, fqdn, ip, port)
+	if err != nil {
+		log.Err(err).Msg(fmt.Sprintf("[bot] error checking ip conflicts of domain %s", fqdn))
+		return ""
+	}
+	if len(conflicts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Warning: %s is also used by %s. Make sure the IP is yours.", net.JoinHostPort(ip, port), strings.Join(conflicts, ", "))
+}
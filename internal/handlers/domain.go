@@ -1,123 +1,231 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
 	"github.com/1k-off/dev-helper-bot/internal/webserver"
 	"github.com/rs/zerolog/log"
 	"strconv"
+	"strings"
 	"time"
 )
 
 const (
-	timeStoreDomainWeek = 2
+	maxLabelSuffix = 20
 )
 
-var (
-	timeStoreDomain = timeStoreDomainWeek * time.Hour * 24 * 7
-)
+// domainUpdateParams lists parameters accepted by DomainUpdate.
+var domainUpdateParams = []string{"expire", "ip", "basic-auth", "full-ssl", "port", "preset", "websocket", "upstream-protocol", "timeout", "max-body", "logs", "wildcard"}
 
-func (h *Handler) DomainCreate(userId, userName, ip string) (*entities.Domain, error) {
-	if err := webserver.CheckIfIpAllowed(h.Webserver.AllowedSubnets, h.Webserver.DeniedIPs, ip); err != nil {
+// IsDomainUpdateParam reports whether s is a parameter accepted by DomainUpdate.
+func IsDomainUpdateParam(s string) bool {
+	for _, p := range domainUpdateParams {
+		if p == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DomainCreate creates a domain for the user. When the name is not empty it is appended to
+// the user's label, so one user may have several domains, e.g. j-doe-api.domain.tld.
+// A custom label replaces the generated one, the name then defaults to the label.
+// The label is generated from the first suitable of userNames. The config is rendered
+// with all the options at once, so the webserver is reloaded only once.
+// The actor runs the command, it is an admin when the domain is created on behalf of the user,
+// and the lifetime limits and IP verification exemption of the actor apply.
+func (h *Handler) DomainCreate(actorId, userId string, userNames []string, opts DomainOptions) (*entities.Domain, error) {
+	if opts.Preset != "" {
+		if err := h.applyPresetOptions(&opts); err != nil {
+			return nil, err
+		}
+	}
+	name, label := opts.Name, opts.Label
+	if err := validateDomainName(name); err != nil {
+		return nil, err
+	}
+	if err := webserver.CheckIfIpAllowed(h.Webserver.AllowedSubnets, h.Webserver.DeniedIPs, opts.IP); err != nil {
+		return nil, err
+	}
+	if err := validatePort(opts.Port); err != nil {
+		return nil, err
+	}
+	if err := h.checkIpVerified(actorId, opts.IP, opts.Port); err != nil {
+		return nil, err
+	}
+	if err := h.checkIpConflict(userId, "", opts.IP, upstreamPort(&entities.Domain{Port: opts.Port, FullSsl: opts.FullSsl})); err != nil {
 		return nil, err
 	}
 
-	fqdn := transformName(userName) + "." + h.Webserver.ParentDomain
-	delDate := time.Now().Add(timeStoreDomain)
-	deleteDate := time.Date(delDate.Year(), delDate.Month(), delDate.Day(), 9, 0, 0, delDate.Nanosecond(), delDate.Location())
+	var fqdn string
+	if label != "" {
+		label = strings.ToLower(label)
+		if err := validateLabel(label); err != nil {
+			return nil, err
+		}
+		if name == "" {
+			name = label
+		}
+		fqdn = label + "." + h.Webserver.ParentDomain
+		if err := h.checkFqdnFree(fqdn); err != nil {
+			return nil, err
+		}
+		if err := h.checkFqdnReserved(userId, fqdn); err != nil {
+			return nil, err
+		}
+	} else {
+		label = generatedLabel(transformName(userNames...), name)
+		var err error
+		fqdn, err = h.freeFqdn(userId, label)
+		if err != nil {
+			return nil, err
+		}
+	}
+	deleteDate, err := h.expirationDate(actorId, "")
+	if err != nil {
+		return nil, err
+	}
 
 	domain := &entities.Domain{
+		Name:      name,
 		FQDN:      fqdn,
-		IP:        ip,
+		IP:        opts.IP,
 		UserId:    userId,
-		UserName:  userName,
+		UserName:  firstNonEmpty(userNames),
 		CreatedAt: time.Now(),
 		DeleteAt:  deleteDate,
-		BasicAuth: true,
-		FullSsl:   false,
-		Port:      "80",
+		BasicAuth: opts.BasicAuth,
+		FullSsl:   opts.FullSsl,
+		Port:      opts.Port,
+		Preset:    opts.Preset,
 	}
 
-	if err := h.Webserver.Service.Create(domain); err != nil {
+	if err = h.Webserver.Service.Create(domain); err != nil {
 		return nil, err
 	}
 
-	err := h.Store.DomainRepository().Create(domain)
+	err = h.Store.DomainRepository().Create(domain)
 	if err != nil {
 		return nil, err
 	}
+	h.releaseOwnReservation(domain.FQDN)
+	h.recordRevision(domain, actorId, "create")
 	log.Info().Msg(fmt.Sprintf("[bot] created domain %s with IP %s. Scheduled delete date: %s.", domain.FQDN, domain.IP, domain.DeleteAt))
 	return domain, nil
 }
 
-func (h *Handler) DomainUpdate(userId, param, value string) (string, error) {
-	d, err := h.Store.DomainRepository().Get(userId)
+// DomainUpdate updates a parameter of the user's domain addressed by selector. The limits
+// and exemptions of the actor apply, as in DomainCreate.
+func (h *Handler) DomainUpdate(actorId, userId, selector, param, value string) (*entities.Domain, error) {
+	d, err := h.findUserDomain(userId, selector)
 	if err != nil {
-		return "", err
-	}
-
-	updateExp := func() {
-		delDate := time.Now().Add(timeStoreDomain)
-		d.DeleteAt = time.Date(delDate.Year(), delDate.Month(), delDate.Day(), 9, 0, 0, delDate.Nanosecond(), delDate.Location())
+		return nil, err
 	}
 
 	switch param {
-	case "":
-		updateExp()
-	case "expire":
-		updateExp()
+	case "", "expire":
+		deleteAt, err := h.expirationDate(actorId, value)
+		if err != nil {
+			return nil, err
+		}
+		d.DeleteAt = deleteAt
 	case "ip":
+		if value == entities.IpModeVpn {
+			// the address is set by DomainSyncVpnIp
+			d.IpMode = entities.IpModeVpn
+			break
+		}
 		ip := value
-		if err = webserver.CheckIfIpAllowed(h.Webserver.AllowedSubnets, h.Webserver.DeniedIPs, ip); err != nil {
-			return "", err
+		if err = h.checkUpstreamIp(actorId, userId, d.FQDN, ip, d.Port, upstreamPort(d)); err != nil {
+			return nil, err
 		}
 		d.IP = ip
+		d.IpMode = ""
 		if err = h.updateNginxConf(d); err != nil {
-			return "", err
+			return nil, err
 		}
 	case "basic-auth":
-		ba, err := strconv.ParseBool(value)
-		if err != nil {
-			return "", err
+		if value == "rotate" {
+			// new credentials are generated when the config is created
+			d.BasicAuth = true
+			d.BasicAuthUser, d.BasicAuthHash = "", ""
+		} else {
+			ba, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, err
+			}
+			d.BasicAuth = ba
 		}
-		d.BasicAuth = ba
 		if err = h.updateNginxConf(d); err != nil {
-			return "", err
+			return nil, err
 		}
 	case "full-ssl":
 		fs, err := strconv.ParseBool(value)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		d.FullSsl = fs
 		if err = h.updateNginxConf(d); err != nil {
-			return "", err
+			return nil, err
 		}
 	case "port":
-		if value == "" {
-			return "", fmt.Errorf("port can't be empty")
+		if err = validatePort(value); err != nil {
+			return nil, err
+		}
+		d.Port = value
+		if err = h.updateNginxConf(d); err != nil {
+			return nil, err
+		}
+	case "websocket", "upstream-protocol", "timeout", "max-body":
+		if err = h.setProxyParam(d, param, value); err != nil {
+			return nil, err
+		}
+		if err = h.updateNginxConf(d); err != nil {
+			return nil, err
 		}
-		portInt, err := strconv.Atoi(value)
+	case "logs":
+		logs, err := strconv.ParseBool(value)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		if portInt < 1 || portInt > 65535 {
-			return "", fmt.Errorf("port must be in range 1-65535")
+		d.Logs = logs
+		if err = h.updateNginxConf(d); err != nil {
+			return nil, err
 		}
-		d.Port = value
+	case "wildcard":
+		wildcard, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		if wildcard {
+			if err = h.checkWildcardSupport(d); err != nil {
+				return nil, err
+			}
+		}
+		d.Wildcard = wildcard
 		if err = h.updateNginxConf(d); err != nil {
-			return "", err
+			return nil, err
+		}
+	case "preset":
+		if err = h.applyPreset(d, value); err != nil {
+			return nil, err
+		}
+		if err = h.updateNginxConf(d); err != nil {
+			return nil, err
 		}
 	default:
-		return "", fmt.Errorf("unknown parameter")
+		return nil, fmt.Errorf("unknown parameter")
 	}
 
 	err = h.Store.DomainRepository().Update(d)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	h.recordRevision(d, actorId, fmt.Sprintf("update %s %s", param, value))
 	log.Info().Msg(fmt.Sprintf("[bot] updated domain %v", d))
-	return "Updated", nil
+	return d, nil
 }
 
 func (h *Handler) updateNginxConf(d *entities.Domain) error {
@@ -131,22 +239,167 @@ func (h *Handler) updateNginxConf(d *entities.Domain) error {
 	return nil
 }
 
-func (h *Handler) DomainDelete(userId string) (string, error) {
-	d, err := h.Store.DomainRepository().Get(userId)
+// revertNginxConf re-creates the config of the domain after its update is refused by the store.
+func (h *Handler) revertNginxConf(d *entities.Domain) {
+	if err := h.updateNginxConf(d); err != nil {
+		log.Err(err).Msg(fmt.Sprintf("[bot] error reverting config of domain %s", d.FQDN))
+	}
+}
+
+// DomainIssueMissingCredentials generates basic auth credentials for domains created before
+// credentials were generated per domain, which have basic auth enabled but no credentials.
+// It returns the domains with BasicAuthPassword set, the password must be delivered to the owners.
+func (h *Handler) DomainIssueMissingCredentials() ([]*entities.Domain, error) {
+	var errors []error
+	domains, err := h.Store.DomainRepository().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var issued []*entities.Domain
+	for _, d := range domains {
+		if !d.BasicAuth || d.BasicAuthHash != "" {
+			continue
+		}
+		if err = h.updateNginxConf(d); err != nil {
+			log.Err(err).Msg(fmt.Sprintf("[bot] error issuing basic auth credentials of domain %s", d.FQDN))
+			errors = append(errors, err)
+			continue
+		}
+		if err = h.Store.DomainRepository().Update(d); err != nil {
+			log.Err(err).Msg(fmt.Sprintf("[bot] error issuing basic auth credentials of domain %s", d.FQDN))
+			errors = append(errors, err)
+			continue
+		}
+		h.recordRevision(d, d.UserId, "basic-auth issue")
+		log.Info().Msg(fmt.Sprintf("[bot] issued basic auth credentials of domain %s", d.FQDN))
+		issued = append(issued, d)
+	}
+	if len(errors) > 0 {
+		return issued, fmt.Errorf("one or more errors occured while issuing basic auth credentials. %v", errors)
+	}
+	return issued, nil
+}
+
+// DomainDelete deletes the user's domain addressed by selector. Co-owners can't delete domains.
+func (h *Handler) DomainDelete(userId, selector string) (*entities.Domain, error) {
+	d, err := h.findOwnDomain(userId, selector)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	err = h.Store.DomainRepository().DeleteByFqdn(d.FQDN)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	h.recordRevision(d, userId, "delete")
 	err = h.Webserver.Service.Delete(d.FQDN)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	h.deleteLogs(d)
+	h.reserveFqdn(d)
 	log.Info().Msg(fmt.Sprintf("[bot] deleted domain %v", d))
-	return fmt.Sprintf("Deleted domain %s", d.FQDN), nil
+	return d, nil
+}
 
+// checkUpstreamIp runs the checks required before the domain with the fqdn proxies to the IP:
+// allowed networks, IP verification and conflicts with domains of other users on the upstream
+// port. The port is the explicit port used in verification instructions and may be empty.
+// The IP is verified for the actor and checked for conflicts with domains of other users than the owner.
+func (h *Handler) checkUpstreamIp(actorId, userId, fqdn, ip, port, upstream string) error {
+	if err := webserver.CheckIfIpAllowed(h.Webserver.AllowedSubnets, h.Webserver.DeniedIPs, ip); err != nil {
+		return err
+	}
+	if err := h.checkIpVerified(actorId, ip, port); err != nil {
+		return err
+	}
+	return h.checkIpConflict(userId, fqdn, ip, upstream)
+}
+
+// checkFqdnFree returns ErrDomainExists if the FQDN is already used by a domain or an alias.
+func (h *Handler) checkFqdnFree(fqdn string) error {
+	d, err := h.Store.DomainRepository().GetByHostname(fqdn)
+	if err == nil {
+		return fmt.Errorf("%w: %s is used by <@%s>", ErrDomainExists, fqdn, d.UserId)
+	}
+	if !errors.Is(err, store.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// freeFqdn returns the FQDN for the generated label. If the label is taken by or reserved
+// for another user, a numeric suffix is added, so two Jane Does get j-doe and j-doe-2.
+func (h *Handler) freeFqdn(userId, label string) (string, error) {
+	for i := 1; i <= maxLabelSuffix; i++ {
+		l := label
+		if i > 1 {
+			l = fmt.Sprintf("%s-%d", label, i)
+		}
+		if len(l) > maxLabelLength {
+			return "", ErrInvalidLabelLength
+		}
+		fqdn := l + "." + h.Webserver.ParentDomain
+		d, err := h.Store.DomainRepository().GetByHostname(fqdn)
+		if errors.Is(err, store.ErrRecordNotFound) {
+			err = h.checkFqdnReserved(userId, fqdn)
+			if errors.Is(err, ErrDomainReserved) {
+				continue
+			}
+			return fqdn, err
+		}
+		if err != nil {
+			return "", err
+		}
+		if d.FQDN != fqdn {
+			// the name is an alias of another domain
+			continue
+		}
+		if d.UserId == userId {
+			return "", fmt.Errorf("%w: %s", ErrDomainExists, d.FQDN)
+		}
+	}
+	return "", fmt.Errorf("%w: all names for %s are taken, choose a custom one", ErrDomainExists, label)
+}
+
+// findUserDomain returns the domain owned or co-owned by the user and addressed by selector.
+// The selector is the domain name or its FQDN and may be empty when the user has only one domain.
+func (h *Handler) findUserDomain(userId, selector string) (*entities.Domain, error) {
+	domains, err := h.Store.DomainRepository().GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	var matched []*entities.Domain
+	for _, d := range domains {
+		if d.FQDN == selector {
+			return d, nil
+		}
+		if selector == "" || d.Name == selector {
+			matched = append(matched, d)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, ErrDomainNotFound
+	case 1:
+		return matched[0], nil
+	}
+	var fqdns []string
+	for _, d := range matched {
+		fqdns = append(fqdns, d.FQDN)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrDomainAmbiguous, strings.Join(fqdns, ", "))
+}
+
+// findOwnDomain is like findUserDomain but allows only the primary owner of the domain.
+func (h *Handler) findOwnDomain(userId, selector string) (*entities.Domain, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return nil, err
+	}
+	if d.UserId != userId {
+		return nil, fmt.Errorf("%w: ask <@%s>", ErrNotDomainOwner, d.UserId)
+	}
+	return d, nil
 }
 
 // DomainGetExpired returns list of expired domains
@@ -167,6 +420,7 @@ func (h *Handler) DomainDeleteExpired() error {
 		return err
 	}
 	for _, d := range domains {
+		h.recordRevision(d, d.UserId, "expire")
 		if err = h.Webserver.Service.Delete(d.FQDN); err != nil {
 			log.Err(err).Msg(fmt.Sprintf("[bot] error deleting domain %v", d))
 			errors = append(errors, err)
@@ -175,10 +429,12 @@ func (h *Handler) DomainDeleteExpired() error {
 			log.Err(err).Msg(fmt.Sprintf("[bot] error deleting domain %v", d))
 			errors = append(errors, err)
 		}
+		h.deleteLogs(d)
+		h.reserveFqdn(d)
 		log.Info().Msg(fmt.Sprintf("[bot] deleted domain %v", d))
 	}
 	if len(errors) > 0 {
-		return fmt.Errorf(fmt.Sprintf("one or more errors occured while deleting domains. %v", errors))
+		return fmt.Errorf("one or more errors occured while deleting domains. %v", errors)
 	}
 	return nil
 }
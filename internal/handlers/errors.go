@@ -0,0 +1,17 @@
+package handlers
+
+import "errors"
+
+var (
+	ErrDomainNotFound     = errors.New("[bot] domain not found")
+	ErrDomainAmbiguous    = errors.New("[bot] you have several domains, specify which one to use")
+	ErrDomainExists       = errors.New("[bot] domain with this name already exists")
+	ErrInvalidDomainName  = errors.New("[bot] domain name may contain only latin letters, digits and single dashes and can't start or end with a dash")
+	ErrInvalidLabelLength = errors.New("[bot] domain label must be from 3 to 63 symbols long")
+	ErrReservedName       = errors.New("[bot] this name is reserved, choose another one")
+	ErrNotDomainOwner     = errors.New("[bot] only the domain owner can do this")
+	ErrDomainReserved     = errors.New("[bot] this name is reserved for the owner of the deleted domain")
+	ErrNoWildcardSupport  = errors.New("[bot] wildcard subdomains are not supported")
+	ErrIpNotVerified      = errors.New("[bot] you haven't verified that you control this IP")
+	ErrIpConflict         = errors.New("[bot] this IP and port are used by a domain of another user")
+)
@@ -14,6 +14,7 @@ type Handler struct {
 	Webserver        config.Webserver
 	Store            store.Store
 	Timezone         *time.Location
+	AdminUserIDs     []string
 }
 
 func New(c, cEU *pritunl.Client, wc config.Webserver, s store.Store, timezone *time.Location, msgTemplates map[string]string) *Handler {
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/rs/zerolog/log"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxDomainHeaders   = 20
+	maxHeaderValueSize = 256
+	// headerValueAny stands for *, which Slack strips from messages
+	headerValueAny = "any"
+)
+
+var (
+	headerNameRegexp = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+	// header values are rendered in double quotes, so double quotes, escapes, nginx variables
+	// and caddy placeholders are not allowed. Single quotes and ; are needed for CSP values.
+	headerValueRegexp = regexp.MustCompile("^[^\"`\\\\{}$\\x00-\\x1f\\x7f]+$")
+
+	// slackUnescaper decodes the symbols Slack escapes in messages
+	slackUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+	// allowedHeaders lists headers that can be set besides custom X- headers
+	allowedHeaders = map[string][]string{
+		entities.HeaderRequest: {
+			"Host",
+			"Origin",
+			"Referer",
+			"X-Forwarded-Host",
+			"X-Forwarded-Proto",
+		},
+		entities.HeaderResponse: {
+			"Access-Control-Allow-Credentials",
+			"Access-Control-Allow-Headers",
+			"Access-Control-Allow-Methods",
+			"Access-Control-Allow-Origin",
+			"Access-Control-Expose-Headers",
+			"Access-Control-Max-Age",
+			"Cache-Control",
+			"Content-Security-Policy",
+			"Referrer-Policy",
+			"X-Frame-Options",
+			"X-Robots-Tag",
+		},
+	}
+	// deniedHeaders are set by the bot itself
+	deniedHeaders = []string{"X-Real-Ip", "X-Forwarded-For"}
+)
+
+// IsHeaderKind reports whether the value is a header kind: request or response.
+func IsHeaderKind(value string) bool {
+	return value == entities.HeaderRequest || value == entities.HeaderResponse
+}
+
+// DomainHeaderSet adds a header rule or replaces the existing one with the same name.
+func (h *Handler) DomainHeaderSet(userId, selector, kind, name, value string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	name, err = validateHeaderName(kind, name)
+	if err != nil {
+		return "", err
+	}
+	value = slackUnescaper.Replace(value)
+	if value == headerValueAny && strings.HasPrefix(name, "Access-Control-") {
+		value = "*"
+	}
+	if err = validateHeaderValue(value); err != nil {
+		return "", err
+	}
+	if d.Wildcard && kind == entities.HeaderRequest && name == "Host" {
+		return "", fmt.Errorf("wildcard domains pass the original Host header to the upstream, disable wildcard first")
+	}
+
+	header := entities.Header{Kind: kind, Name: name, Value: value}
+	replaced := false
+	for i, hd := range d.Headers {
+		if hd.Kind == kind && hd.Name == name {
+			d.Headers[i] = header
+			replaced = true
+		}
+	}
+	if !replaced {
+		if len(d.Headers) >= maxDomainHeaders {
+			return "", fmt.Errorf("domain can't have more than %d headers", maxDomainHeaders)
+		}
+		d.Headers = append(d.Headers, header)
+	}
+
+	if err = h.updateNginxConf(d); err != nil {
+		return "", err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return "", err
+	}
+	h.recordRevision(d, userId, fmt.Sprintf("header set %s %s %s", kind, name, value))
+	log.Info().Msg(fmt.Sprintf("[bot] set %s header %s: %s on domain %s", kind, name, value, d.FQDN))
+	return fmt.Sprintf("Header %s: %s set for %ss of %s", name, value, kind, d.FQDN), nil
+}
+
+// DomainHeaderUnset removes the header rule.
+func (h *Handler) DomainHeaderUnset(userId, selector, kind, name string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	name = textproto.CanonicalMIMEHeaderKey(name)
+
+	var headers []entities.Header
+	for _, hd := range d.Headers {
+		if hd.Kind != kind || hd.Name != name {
+			headers = append(headers, hd)
+		}
+	}
+	if len(headers) == len(d.Headers) {
+		return "", fmt.Errorf("%s header %s is not set", kind, name)
+	}
+	d.Headers = headers
+
+	if err = h.updateNginxConf(d); err != nil {
+		return "", err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return "", err
+	}
+	h.recordRevision(d, userId, fmt.Sprintf("header unset %s %s", kind, name))
+	log.Info().Msg(fmt.Sprintf("[bot] unset %s header %s on domain %s", kind, name, d.FQDN))
+	return fmt.Sprintf("Header %s removed from %ss of %s", name, kind, d.FQDN), nil
+}
+
+// DomainHeaderList returns a human-readable list of the domain headers.
+func (h *Handler) DomainHeaderList(userId, selector string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	if len(d.Headers) == 0 {
+		return fmt.Sprintf("%s has no custom headers", d.FQDN), nil
+	}
+	var lines []string
+	for _, hd := range d.Headers {
+		lines = append(lines, fmt.Sprintf("%s %s: %s", hd.Kind, hd.Name, hd.Value))
+	}
+	return fmt.Sprintf("Headers of %s:\n%s", d.FQDN, strings.Join(lines, "\n")), nil
+}
+
+// validateHeaderName checks the header against the allowlist and returns its canonical name.
+func validateHeaderName(kind, name string) (string, error) {
+	if !IsHeaderKind(kind) {
+		return "", fmt.Errorf("header kind must be request or response")
+	}
+	if !headerNameRegexp.MatchString(name) {
+		return "", fmt.Errorf("invalid header name %q", name)
+	}
+	name = textproto.CanonicalMIMEHeaderKey(name)
+	for _, denied := range deniedHeaders {
+		if name == denied {
+			return "", fmt.Errorf("header %s is set by the bot and can't be changed", name)
+		}
+	}
+	if strings.HasPrefix(name, "X-") {
+		return name, nil
+	}
+	for _, allowed := range allowedHeaders[kind] {
+		if name == allowed {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%s header %s is not allowed. Allowed headers: %s and custom X- headers", kind, name, strings.Join(allowedHeaders[kind], ", "))
+}
+
+func validateHeaderValue(value string) error {
+	if len(value) > maxHeaderValueSize {
+		return fmt.Errorf("header value can't be longer than %d symbols", maxHeaderValueSize)
+	}
+	if strings.TrimSpace(value) == "" || !headerValueRegexp.MatchString(value) {
+		return fmt.Errorf("invalid header value %q. Double quotes, backticks, backslashes and { } $ are not allowed", value)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"strings"
+	"testing"
+)
+
+func TestValidateHeaderName(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{name: "custom request header", kind: entities.HeaderRequest, header: "x-env", want: "X-Env"},
+		{name: "custom response header", kind: entities.HeaderResponse, header: "X-Robots-Tag", want: "X-Robots-Tag"},
+		{name: "allowed request header", kind: entities.HeaderRequest, header: "host", want: "Host"},
+		{name: "allowed response header", kind: entities.HeaderResponse, header: "content-security-policy", want: "Content-Security-Policy"},
+		{name: "response header as request header", kind: entities.HeaderRequest, header: "Cache-Control", wantErr: true},
+		{name: "not allowed header", kind: entities.HeaderResponse, header: "Set-Cookie", wantErr: true},
+		{name: "header set by the bot", kind: entities.HeaderRequest, header: "X-Forwarded-For", wantErr: true},
+		{name: "unknown kind", kind: "upstream", header: "X-Env", wantErr: true},
+		{name: "space in name", kind: entities.HeaderRequest, header: "X-Env Host", wantErr: true},
+		{name: "colon in name", kind: entities.HeaderRequest, header: "X-Env:", wantErr: true},
+		{name: "config syntax in name", kind: entities.HeaderRequest, header: "X-Env;", wantErr: true},
+		{name: "newline in name", kind: entities.HeaderRequest, header: "X-Env\nHost", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateHeaderName(tt.kind, tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateHeaderName(%q, %q) error = %v, wantErr %v", tt.kind, tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("validateHeaderName(%q, %q) = %q, want %q", tt.kind, tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateHeaderValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "plain value", value: "noindex"},
+		{name: "origin", value: "https://app.example.com"},
+		{name: "list", value: "GET, POST, OPTIONS"},
+		{name: "csp with quotes and semicolons", value: "default-src 'self'; img-src 'self' data:"},
+		{name: "unescaped slack symbols", value: slackUnescaper.Replace("a &amp; b &lt;c&gt;")},
+		{name: "empty", value: "", wantErr: true},
+		{name: "spaces only", value: "   ", wantErr: true},
+		{name: "double quote", value: `x" always; return 200 "`, wantErr: true},
+		{name: "backslash", value: `x\"`, wantErr: true},
+		{name: "backtick", value: "x`", wantErr: true},
+		{name: "nginx variable", value: "$http_cookie", wantErr: true},
+		{name: "caddy placeholder", value: "{env.SECRET}", wantErr: true},
+		{name: "closing block", value: "x }", wantErr: true},
+		{name: "newline", value: "x\nreturn 200", wantErr: true},
+		{name: "carriage return", value: "x\rSet-Cookie: a", wantErr: true},
+		{name: "null byte", value: "x\x00", wantErr: true},
+		{name: "too long", value: strings.Repeat("a", maxHeaderValueSize+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateHeaderValue(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("validateHeaderValue(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/rs/zerolog/log"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	healthCheckTimeout     = 5 * time.Second
+	healthCheckConcurrency = 10
+)
+
+// DomainCheckHealth probes upstreams of all active domains, stores the results and returns
+// the checked domains with the new health status.
+func (h *Handler) DomainCheckHealth() ([]*entities.Domain, error) {
+	domains, err := h.Store.DomainRepository().GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var checked []*entities.Domain
+	for _, d := range domains {
+		if !d.IsPaused() {
+			checked = append(checked, d)
+		}
+	}
+	domains = checked
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, healthCheckConcurrency)
+	for _, d := range domains {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d *entities.Domain) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.Health = probeUpstream(UpstreamAddress(d))
+		}(d)
+	}
+	wg.Wait()
+
+	for _, d := range domains {
+		if err = h.Store.DomainRepository().UpdateHealth(d.FQDN, d.Health); err != nil {
+			log.Err(err).Msgf("[bot] error saving health of domain %s", d.FQDN)
+		}
+	}
+	return domains, nil
+}
+
+// upstreamPort returns the port the webserver proxies the domain to.
+func upstreamPort(d *entities.Domain) string {
+	if d.Port != "" {
+		return d.Port
+	}
+	if d.FullSsl {
+		return "443"
+	}
+	return "80"
+}
+
+// UpstreamAddress returns the host:port address serving the root of the domain,
+// a route of the / path overrides the domain upstream.
+func UpstreamAddress(d *entities.Domain) string {
+	for _, r := range d.Routes {
+		if r.Path == "/" {
+			return net.JoinHostPort(r.IP, r.Port)
+		}
+	}
+	return net.JoinHostPort(d.IP, upstreamPort(d))
+}
+
+// probeUpstream opens a TCP connection to the upstream and measures how long it takes.
+func probeUpstream(address string) entities.Health {
+	start := time.Now()
+	health := entities.Health{Status: entities.HealthUp, CheckedAt: start}
+	conn, err := net.DialTimeout("tcp", address, healthCheckTimeout)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Status = entities.HealthDown
+		health.Error = err.Error()
+		return health
+	}
+	if err = conn.Close(); err != nil {
+		log.Debug().Msg(fmt.Sprintf("[bot] error closing health check connection to %s: %v", address, err))
+	}
+	return health
+}
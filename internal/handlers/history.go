@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+const historyListLimit = 20
+
+// recordRevision appends the domain state and its rendered config to the domain history.
+// Errors are only logged, the change itself is already applied.
+func (h *Handler) recordRevision(d *entities.Domain, actor, action string) {
+	config, err := h.Webserver.Service.Config(d.FQDN)
+	if err != nil {
+		log.Err(err).Msg(fmt.Sprintf("[bot] error reading config of domain %s", d.FQDN))
+	}
+	revision := &entities.Revision{
+		FQDN:      d.FQDN,
+		Actor:     actor,
+		Action:    strings.TrimSpace(action),
+		Domain:    *d,
+		Config:    config,
+		CreatedAt: time.Now(),
+	}
+	revision.Domain.Health = entities.Health{}
+	if err = h.Store.HistoryRepository().Append(revision); err != nil {
+		log.Err(err).Msg(fmt.Sprintf("[bot] error saving revision of domain %s", d.FQDN))
+	}
+}
+
+// DomainHistory returns a human-readable list of the last revisions of the domain.
+func (h *Handler) DomainHistory(userId, selector string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	revisions, err := h.Store.HistoryRepository().GetAllByDomainId(d.Id, historyListLimit)
+	if err != nil {
+		return "", err
+	}
+	if len(revisions) == 0 {
+		return fmt.Sprintf("Domain %s has no history yet", d.FQDN), nil
+	}
+	lines := []string{fmt.Sprintf("History of %s (last %d changes):", d.FQDN, historyListLimit)}
+	for _, r := range revisions {
+		lines = append(lines, fmt.Sprintf("#%d %s <@%s>: %s", r.Rev, r.CreatedAt.In(h.Timezone).Format("2006-01-02 15:04"), r.Actor, r.Action))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// DomainRollback restores settings of the domain saved in the revision and re-creates its config.
+// Ownership, co-owners, the expiration date and basic auth credentials are kept as they are.
+// Restored upstreams, aliases and wildcard pass the same checks as when they are set directly.
+func (h *Handler) DomainRollback(userId, selector string, rev int) (*entities.Domain, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := h.Store.HistoryRepository().GetByRev(d.Id, rev)
+	if err != nil {
+		if errors.Is(err, store.ErrRecordNotFound) {
+			return nil, fmt.Errorf("revision #%d of %s not found, see `domain history`", rev, d.FQDN)
+		}
+		return nil, err
+	}
+
+	restored := *d
+	old := revision.Domain
+	restored.IP, restored.IpMode, restored.Port, restored.FullSsl = old.IP, old.IpMode, old.Port, old.FullSsl
+	restored.BasicAuth, restored.Routes, restored.Access, restored.State = old.BasicAuth, old.Routes, old.Access, old.State
+	restored.Preset, restored.Proxy, restored.Headers, restored.Logs = old.Preset, old.Proxy, old.Headers, old.Logs
+	restored.Wildcard, restored.Aliases = old.Wildcard, old.Aliases
+	if err = h.checkRestored(userId, d, &restored); err != nil {
+		return nil, fmt.Errorf("can't restore revision #%d: %w", rev, err)
+	}
+
+	if err = h.updateNginxConf(&restored); err != nil {
+		return nil, err
+	}
+	if err = h.Store.DomainRepository().Update(&restored); err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			// another domain took a restored alias after the check
+			h.revertNginxConf(d)
+			return nil, fmt.Errorf("can't restore revision #%d: %w", rev, ErrDomainExists)
+		}
+		return nil, err
+	}
+	h.recordRevision(&restored, userId, fmt.Sprintf("rollback to #%d", rev))
+	log.Info().Msg(fmt.Sprintf("[bot] domain %s rolled back to revision %d by %s", d.FQDN, rev, userId))
+	return &restored, nil
+}
+
+// checkRestored validates settings of the current domain which differ in the restored one.
+func (h *Handler) checkRestored(userId string, current, restored *entities.Domain) error {
+	if restored.IP != current.IP || upstreamPort(restored) != upstreamPort(current) {
+		if err := h.checkUpstreamIp(userId, userId, restored.FQDN, restored.IP, restored.Port, upstreamPort(restored)); err != nil {
+			return err
+		}
+	}
+	for _, r := range restored.Routes {
+		if !hasRoute(current.Routes, r) {
+			if err := h.checkUpstreamIp(userId, userId, restored.FQDN, r.IP, r.Port, r.Port); err != nil {
+				return err
+			}
+		}
+	}
+	for _, alias := range restored.Aliases {
+		if hasAlias(current.Aliases, alias) {
+			continue
+		}
+		hostname, internal, err := h.aliasHostname(alias)
+		if err != nil {
+			return err
+		}
+		if err = h.checkAliasAvailable(userId, hostname, internal); err != nil {
+			return err
+		}
+	}
+	if restored.Wildcard && !current.Wildcard {
+		return h.checkWildcardSupport(restored)
+	}
+	return nil
+}
+
+func hasRoute(routes []entities.Route, route entities.Route) bool {
+	for _, r := range routes {
+		if r.IP == route.IP && r.Port == route.Port {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAlias(aliases []string, alias string) bool {
+	for _, a := range aliases {
+		if a == alias {
+			return true
+		}
+	}
+	return false
+}
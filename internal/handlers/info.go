@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/webserver"
+)
+
+// DomainInfo is a summary of the domain settings shown to its owners.
+type DomainInfo struct {
+	Domain *entities.Domain
+	// Scheme and Port address the upstream the domain is proxied to
+	Scheme string
+	Port   string
+}
+
+// DomainInfo returns the summary of the user's domain addressed by selector.
+func (h *Handler) DomainInfo(userId, selector string) (*DomainInfo, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return nil, err
+	}
+	scheme := webserver.SchemeHttp
+	if d.FullSsl {
+		scheme = webserver.SchemeHttps
+	}
+	switch {
+	case d.Proxy.Protocol == webserver.ProtocolGrpc && d.FullSsl:
+		scheme = "grpcs"
+	case d.Proxy.Protocol != "":
+		scheme = d.Proxy.Protocol
+	}
+	return &DomainInfo{Domain: d, Scheme: scheme, Port: upstreamPort(d)}, nil
+}
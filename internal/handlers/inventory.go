@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/config"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DomainListPageSize is the number of domains on a page of the inventory.
+const DomainListPageSize = 20
+
+// domainListSortKeys maps sort option values to domain keys.
+var domainListSortKeys = map[string]string{
+	"fqdn":    store.DomainFqdnKey,
+	"owner":   store.DomainUserIdKey,
+	"ip":      store.DomainIpKey,
+	"created": store.DomainCreatedAtKey,
+	"expires": store.DomainDeleteAtKey,
+}
+
+// DomainListQuery is an admin query of the domain inventory.
+type DomainListQuery struct {
+	Filter store.DomainFilter
+	// Page is numbered from 1
+	Page int
+	// CSV selects all matching domains instead of a page
+	CSV bool
+}
+
+// ParseDomainListQuery parses options like owner=U123, subnet=10.0.0.0/24, expires-before=7d,
+// auth=false, ssl=true, sort=expires, page=2 and the csv flag.
+func (h *Handler) ParseDomainListQuery(args []string) (DomainListQuery, error) {
+	q := DomainListQuery{Page: 1}
+	for _, arg := range args {
+		if arg == "csv" {
+			q.CSV = true
+			continue
+		}
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return q, fmt.Errorf("invalid option %q, expected key=value", arg)
+		}
+		var err error
+		switch key {
+		case "owner":
+			q.Filter.UserId = value
+		case "subnet":
+			var cidr string
+			if cidr, err = normalizeCidr(value); err == nil {
+				_, q.Filter.Subnet, err = net.ParseCIDR(cidr)
+			}
+		case "expires-before":
+			q.Filter.ExpiresBefore, err = h.parseDate(value)
+		case "auth":
+			var auth bool
+			auth, err = strconv.ParseBool(value)
+			q.Filter.BasicAuth = &auth
+		case "ssl":
+			var ssl bool
+			ssl, err = strconv.ParseBool(value)
+			q.Filter.FullSsl = &ssl
+		case "sort":
+			var known bool
+			if q.Filter.SortBy, known = domainListSortKeys[value]; !known {
+				err = fmt.Errorf("sort must be one of: fqdn, owner, ip, created, expires")
+			}
+		case "page":
+			q.Page, err = strconv.Atoi(value)
+			if err == nil && q.Page < 1 {
+				err = fmt.Errorf("page must be positive")
+			}
+		default:
+			return q, fmt.Errorf("unknown option %q, available options: owner, subnet, expires-before, auth, ssl, sort, page, csv", key)
+		}
+		if err != nil {
+			return q, fmt.Errorf("invalid value of option %s: %v", key, err)
+		}
+	}
+	return q, nil
+}
+
+// parseDate parses a date like 2025-12-01 or a duration from now like 5d.
+func (h *Handler) parseDate(value string) (time.Time, error) {
+	if date, err := time.ParseInLocation(expireDateFormat, value, h.Timezone); err == nil {
+		return date, nil
+	}
+	d, err := config.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w. Use a duration like 12h, 5d, 2w or a date like %s", err, expireDateFormat)
+	}
+	return time.Now().Add(d), nil
+}
+
+// DomainList returns the page of the domain inventory selected by the query and the total number
+// of matching domains. CSV queries return all matching domains.
+func (h *Handler) DomainList(q DomainListQuery) ([]*entities.Domain, int64, error) {
+	filter := q.Filter
+	if !q.CSV {
+		filter.Offset = int64(q.Page-1) * DomainListPageSize
+		filter.Limit = DomainListPageSize
+	}
+	return h.Store.DomainRepository().List(filter)
+}
+
+// DomainListCSV renders the domains as a CSV table.
+func (h *Handler) DomainListCSV(domains []*entities.Domain) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"fqdn", "name", "ip", "port", "owner_id", "owner_name", "co_owners", "created_at", "delete_at", "basic_auth", "full_ssl", "state", "preset", "health"}}
+	for _, d := range domains {
+		state := d.State
+		if state == "" {
+			state = entities.StateActive
+		}
+		rows = append(rows, []string{
+			d.FQDN,
+			d.Name,
+			d.IP,
+			upstreamPort(d),
+			d.UserId,
+			d.UserName,
+			strings.Join(d.CoOwners, " "),
+			d.CreatedAt.In(h.Timezone).Format(time.RFC3339),
+			d.DeleteAt.In(h.Timezone).Format(time.RFC3339),
+			strconv.FormatBool(d.BasicAuth),
+			strconv.FormatBool(d.FullSsl),
+			state,
+			d.Preset,
+			d.Health.Status,
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
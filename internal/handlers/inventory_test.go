@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDomainListQuery(t *testing.T) {
+	h := &Handler{Timezone: time.FixedZone("EET", 2*60*60)}
+	yes, no := true, false
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	_, host, _ := net.ParseCIDR("10.0.0.5/32")
+	tests := []struct {
+		name    string
+		args    []string
+		want    DomainListQuery
+		wantErr bool
+	}{
+		{name: "no options", want: DomainListQuery{Page: 1}},
+		{name: "csv", args: []string{"csv"}, want: DomainListQuery{Page: 1, CSV: true}},
+		{name: "owner", args: []string{"owner=U123"}, want: DomainListQuery{Page: 1, Filter: store.DomainFilter{UserId: "U123"}}},
+		{name: "subnet", args: []string{"subnet=10.0.0.7/24"}, want: DomainListQuery{Page: 1, Filter: store.DomainFilter{Subnet: subnet}}},
+		{name: "subnet of an ip", args: []string{"subnet=10.0.0.5"}, want: DomainListQuery{Page: 1, Filter: store.DomainFilter{Subnet: host}}},
+		{
+			name: "expires before a date",
+			args: []string{"expires-before=2025-12-01"},
+			want: DomainListQuery{Page: 1, Filter: store.DomainFilter{ExpiresBefore: time.Date(2025, 12, 1, 0, 0, 0, 0, h.Timezone)}},
+		},
+		{
+			name: "flags, sort and page",
+			args: []string{"auth=false", "ssl=true", "sort=expires", "page=3"},
+			want: DomainListQuery{Page: 3, Filter: store.DomainFilter{BasicAuth: &no, FullSsl: &yes, SortBy: store.DomainDeleteAtKey}},
+		},
+		{name: "no value", args: []string{"owner="}, wantErr: true},
+		{name: "no separator", args: []string{"owner"}, wantErr: true},
+		{name: "unknown option", args: []string{"state=paused"}, wantErr: true},
+		{name: "invalid subnet", args: []string{"subnet=10.0.0.0/33"}, wantErr: true},
+		{name: "invalid date", args: []string{"expires-before=soon"}, wantErr: true},
+		{name: "invalid flag", args: []string{"auth=maybe"}, wantErr: true},
+		{name: "unknown sort key", args: []string{"sort=name"}, wantErr: true},
+		{name: "zero page", args: []string{"page=0"}, wantErr: true},
+		{name: "page is not a number", args: []string{"page=last"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.ParseDomainListQuery(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDomainListQuery(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDomainListQuery(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDomainListQueryExpiresIn(t *testing.T) {
+	h := &Handler{Timezone: time.UTC}
+	got, err := h.ParseDomainListQuery([]string{"expires-before=7d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Now().Add(7 * 24 * time.Hour)
+	if got.Filter.ExpiresBefore.Sub(want).Abs() > time.Minute {
+		t.Errorf("ParseDomainListQuery(expires-before=7d) expires before %s, want %s", got.Filter.ExpiresBefore, want)
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/config"
+	"time"
+)
+
+const expireDateFormat = "2006-01-02"
+
+// userRole returns the role used to pick per-role settings from the config
+func (h *Handler) userRole(userId string) string {
+	if h.IsAdmin(userId) {
+		return config.RoleAdmin
+	}
+	return ""
+}
+
+// expirationDate returns the delete date for the value of the expire parameter.
+// Empty value means the default lifetime, otherwise the value is a duration
+// like 12h, 5d, 2w or a date like 2025-12-01. Dates and durations in whole days
+// are moved to 09:00, so domains are deleted in the morning.
+func (h *Handler) expirationDate(userId, value string) (time.Time, error) {
+	def, max := h.Webserver.Lifetime.For(h.userRole(userId))
+	now := time.Now().In(h.Timezone)
+	latest := now.Add(max)
+	if morning := atNineAm(latest); morning.After(latest) {
+		latest = morning
+	}
+
+	var deleteAt time.Time
+	if date, err := time.ParseInLocation(expireDateFormat, value, h.Timezone); err == nil {
+		deleteAt = atNineAm(date)
+	} else {
+		d := def
+		if value != "" {
+			d, err = config.ParseDuration(value)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w. Use a duration like 12h, 5d, 2w or a date like %s", err, expireDateFormat)
+			}
+		}
+		if d > max {
+			return time.Time{}, fmt.Errorf("domain lifetime can't be longer than %s", formatDuration(max))
+		}
+		deleteAt = now.Add(d)
+		if d%(24*time.Hour) == 0 {
+			deleteAt = atNineAm(deleteAt)
+		}
+	}
+
+	if !deleteAt.After(now) {
+		return time.Time{}, fmt.Errorf("delete date %s is in the past", deleteAt.Format(expireDateFormat))
+	}
+	if deleteAt.After(latest) {
+		return time.Time{}, fmt.Errorf("domain lifetime can't be longer than %s, latest delete date is %s", formatDuration(max), latest.Format(expireDateFormat))
+	}
+	return deleteAt, nil
+}
+
+func atNineAm(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 9, 0, 0, 0, t.Location())
+}
+
+// formatDuration prints whole days as days, e.g. 14d instead of 336h0m0s
+func formatDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
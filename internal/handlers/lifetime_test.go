@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"github.com/1k-off/dev-helper-bot/internal/config"
+	"testing"
+	"time"
+)
+
+func TestExpirationDate(t *testing.T) {
+	h := &Handler{
+		Webserver: config.Webserver{Lifetime: config.DomainLifetime{
+			Default: "7d",
+			Max:     "2w",
+			Roles:   map[string]config.DomainLifetime{config.RoleAdmin: {Max: "30d"}},
+		}},
+		Timezone:     time.FixedZone("EET", 2*60*60),
+		AdminUserIDs: []string{"UADMIN"},
+	}
+	day := 24 * time.Hour
+	tests := []struct {
+		name   string
+		userId string
+		value  string
+		// want returns the expected delete date, nil means an error is expected
+		want func(now time.Time) time.Time
+	}{
+		{name: "default lifetime", value: "", want: func(now time.Time) time.Time { return atNineAm(now.Add(7 * day)) }},
+		{name: "hours", value: "12h", want: func(now time.Time) time.Time { return now.Add(12 * time.Hour) }},
+		{name: "days", value: "5d", want: func(now time.Time) time.Time { return atNineAm(now.Add(5 * day)) }},
+		{name: "weeks up to max", value: "2w", want: func(now time.Time) time.Time { return atNineAm(now.Add(14 * day)) }},
+		{name: "date", value: time.Now().In(h.Timezone).Add(3 * day).Format(expireDateFormat), want: func(now time.Time) time.Time {
+			return atNineAm(now.Add(3 * day))
+		}},
+		{name: "longer than max", value: "15d"},
+		{name: "date after max", value: time.Now().In(h.Timezone).Add(20 * day).Format(expireDateFormat)},
+		{name: "date in the past", value: "2020-01-01"},
+		{name: "zero duration", value: "0d"},
+		{name: "negative duration", value: "-5h"},
+		{name: "fractional days", value: "1.5d"},
+		{name: "not a duration", value: "tomorrow"},
+		{name: "admin max", userId: "UADMIN", value: "30d", want: func(now time.Time) time.Time { return atNineAm(now.Add(30 * day)) }},
+		{name: "admin default", userId: "UADMIN", value: "", want: func(now time.Time) time.Time { return atNineAm(now.Add(7 * day)) }},
+		{name: "longer than admin max", userId: "UADMIN", value: "5w"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now().In(h.Timezone)
+			got, err := h.expirationDate(tt.userId, tt.value)
+			if (err != nil) != (tt.want == nil) {
+				t.Fatalf("expirationDate(%q, %q) error = %v, wantErr %v", tt.userId, tt.value, err, tt.want == nil)
+			}
+			if tt.want == nil {
+				return
+			}
+			if want := tt.want(now); got.Sub(want).Abs() > time.Minute {
+				t.Errorf("expirationDate(%q, %q) = %s, want %s", tt.userId, tt.value, got, want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 14 * 24 * time.Hour, want: "14d"},
+		{d: 36 * time.Hour, want: "36h0m0s"},
+		{d: 90 * time.Minute, want: "1h30m0s"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultLogLines = 50
+	maxLogLines     = 200
+)
+
+// DomainLogs returns the last lines of the access log of the domain, or of the error log if errors is set.
+func (h *Handler) DomainLogs(userId, selector string, lines int, errors bool) (*entities.Domain, string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return nil, "", err
+	}
+	if !d.Logs {
+		return nil, "", fmt.Errorf("logs are disabled for domain %s, enable them with `domain update logs true`", d.FQDN)
+	}
+	if lines <= 0 {
+		lines = defaultLogLines
+	}
+	if lines > maxLogLines {
+		lines = maxLogLines
+	}
+	content, err := h.Webserver.Service.Logs(d.FQDN, lines, errors)
+	if err != nil {
+		return nil, "", err
+	}
+	return d, content, nil
+}
+
+// RotateLogs rotates domain logs that exceed the configured size.
+func (h *Handler) RotateLogs() error {
+	return h.Webserver.Service.RotateLogs()
+}
+
+// deleteLogs removes log files of the deleted domain. Errors are only logged, the domain is already gone.
+func (h *Handler) deleteLogs(d *entities.Domain) {
+	if err := h.Webserver.Service.DeleteLogs(d.FQDN); err != nil {
+		log.Err(err).Msg(fmt.Sprintf("[bot] error deleting logs of domain %s", d.FQDN))
+	}
+}
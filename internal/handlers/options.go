@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DomainOptions are the settings of a new domain.
+type DomainOptions struct {
+	// Name is appended to the generated label, e.g. j-doe-api
+	Name string
+	// Label replaces the generated label
+	Label     string
+	IP        string
+	Port      string
+	FullSsl   bool
+	BasicAuth bool
+	// Preset provides settings that are not set explicitly
+	Preset string
+	// explicit holds options set by the user, they take precedence over the preset
+	explicit map[string]bool
+}
+
+// NewDomainOptions returns options of a domain with default settings.
+func NewDomainOptions(ip string) DomainOptions {
+	return DomainOptions{
+		IP:        ip,
+		Port:      "80",
+		BasicAuth: true,
+	}
+}
+
+// IsDomainOption reports whether the argument looks like a key=value option.
+func IsDomainOption(arg string) bool {
+	return strings.Contains(arg, "=")
+}
+
+// Set applies a key=value option like port=3000, ssl=true, auth=false or preset=nextjs.
+func (o *DomainOptions) Set(option string) error {
+	key, value, _ := strings.Cut(option, "=")
+	var err error
+	switch key {
+	case "port":
+		err = validatePort(value)
+		o.Port = value
+	case "ssl", "full-ssl":
+		key = "ssl"
+		o.FullSsl, err = strconv.ParseBool(value)
+	case "auth", "basic-auth":
+		key = "auth"
+		o.BasicAuth, err = strconv.ParseBool(value)
+	case "preset":
+		o.Preset = value
+	default:
+		return fmt.Errorf("unknown option %q, available options: port, ssl, auth, preset", key)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value of option %s: %v", key, err)
+	}
+	if o.explicit == nil {
+		o.explicit = map[string]bool{}
+	}
+	o.explicit[key] = true
+	return nil
+}
+
+func validatePort(value string) error {
+	if value == "" {
+		return fmt.Errorf("port can't be empty")
+	}
+	portInt, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	if portInt < 1 || portInt > 65535 {
+		return fmt.Errorf("port must be in range 1-65535")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/rs/zerolog/log"
+)
+
+// DomainPause stops proxying the domain and serves a holding page instead.
+// The domain keeps its name and expiration date.
+func (h *Handler) DomainPause(userId, selector string) (*entities.Domain, error) {
+	return h.setDomainState(userId, selector, entities.StatePaused)
+}
+
+// DomainResume proxies the paused domain to its upstream again.
+func (h *Handler) DomainResume(userId, selector string) (*entities.Domain, error) {
+	return h.setDomainState(userId, selector, entities.StateActive)
+}
+
+func (h *Handler) setDomainState(userId, selector, state string) (*entities.Domain, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return nil, err
+	}
+	if d.IsPaused() == (state == entities.StatePaused) {
+		return nil, fmt.Errorf("domain %s is already %s", d.FQDN, state)
+	}
+	d.State = state
+	if err = h.updateNginxConf(d); err != nil {
+		return nil, err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return nil, err
+	}
+	h.recordRevision(d, userId, "state "+state)
+	log.Info().Msg(fmt.Sprintf("[bot] domain %s is %s by %s", d.FQDN, state, userId))
+	return d, nil
+}
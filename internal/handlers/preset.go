@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/webserver"
+	"sort"
+	"strings"
+)
+
+// presetNone removes the preset from the domain
+const presetNone = "none"
+
+// preset returns the preset configured by admins.
+func (h *Handler) preset(name string) (webserver.Preset, error) {
+	p, ok := h.Webserver.Presets[strings.ToLower(name)]
+	if !ok {
+		if len(h.Webserver.Presets) == 0 {
+			return p, fmt.Errorf("there are no presets configured")
+		}
+		return p, fmt.Errorf("unknown preset %q, available presets: %s", name, strings.Join(h.PresetNames(), ", "))
+	}
+	return p, nil
+}
+
+// PresetNames returns names of the configured presets in alphabetical order.
+func (h *Handler) PresetNames() []string {
+	var names []string
+	for name := range h.Webserver.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyPresetOptions fills options that are not set explicitly from the preset.
+func (h *Handler) applyPresetOptions(opts *DomainOptions) error {
+	p, err := h.preset(opts.Preset)
+	if err != nil {
+		return err
+	}
+	opts.Preset = strings.ToLower(opts.Preset)
+	if p.Port != "" && !opts.explicit["port"] {
+		opts.Port = p.Port
+	}
+	if p.Scheme != "" && !opts.explicit["ssl"] {
+		opts.FullSsl = p.Scheme == webserver.SchemeHttps
+	}
+	if p.BasicAuth != nil && !opts.explicit["auth"] {
+		opts.BasicAuth = *p.BasicAuth
+	}
+	return nil
+}
+
+// applyPreset sets the preset of the domain and copies its port, scheme and auth settings.
+func (h *Handler) applyPreset(d *entities.Domain, name string) error {
+	if name == presetNone {
+		d.Preset = ""
+		return nil
+	}
+	p, err := h.preset(name)
+	if err != nil {
+		return err
+	}
+	d.Preset = strings.ToLower(name)
+	if p.Port != "" {
+		d.Port = p.Port
+	}
+	if p.Scheme != "" {
+		d.FullSsl = p.Scheme == webserver.SchemeHttps
+	}
+	if p.BasicAuth != nil {
+		d.BasicAuth = *p.BasicAuth
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/webserver"
+	"strconv"
+)
+
+// proxyDefault resets a proxy setting to the webserver default
+const proxyDefault = "default"
+
+// setProxyParam validates and sets the proxy setting of the domain.
+func (h *Handler) setProxyParam(d *entities.Domain, param, value string) error {
+	proxy := d.Proxy
+	fullSsl := d.FullSsl
+	switch param {
+	case "websocket":
+		ws, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		proxy.Websocket = ws
+	case "upstream-protocol":
+		switch value {
+		case webserver.SchemeHttp, webserver.SchemeHttps:
+			proxy.Protocol = ""
+			fullSsl = value == webserver.SchemeHttps
+		case webserver.ProtocolH2c:
+			if h.Webserver.Kind == webserver.ServerNginx {
+				return fmt.Errorf("nginx can't proxy to h2c upstreams, use grpc for gRPC services")
+			}
+			proxy.Protocol = value
+			fullSsl = false
+		case webserver.ProtocolGrpc:
+			proxy.Protocol = value
+		default:
+			return fmt.Errorf("upstream protocol must be one of: http, https, h2c, grpc")
+		}
+	case "timeout":
+		if value != proxyDefault {
+			if err := webserver.ValidateTimeout(value); err != nil {
+				return err
+			}
+		}
+		proxy.Timeout = value
+	case "max-body":
+		if value != proxyDefault {
+			if err := webserver.ValidateBodySize(value); err != nil {
+				return err
+			}
+		}
+		proxy.MaxBody = value
+	default:
+		return fmt.Errorf("unknown parameter")
+	}
+	if proxy.Timeout == proxyDefault {
+		proxy.Timeout = ""
+	}
+	if proxy.MaxBody == proxyDefault {
+		proxy.MaxBody = ""
+	}
+	if proxy.Websocket && proxy.Protocol == webserver.ProtocolGrpc {
+		return fmt.Errorf("websocket can't be enabled for grpc upstreams")
+	}
+	d.Proxy = proxy
+	d.FullSsl = fullSsl
+	return nil
+}
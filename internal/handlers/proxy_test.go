@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/1k-off/dev-helper-bot/internal/config"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/webserver"
+	"testing"
+)
+
+func TestSetProxyParam(t *testing.T) {
+	grpc := entities.Proxy{Protocol: webserver.ProtocolGrpc}
+	tests := []struct {
+		name        string
+		kind        string
+		proxy       entities.Proxy
+		fullSsl     bool
+		param       string
+		value       string
+		wantProxy   entities.Proxy
+		wantFullSsl bool
+		wantErr     bool
+	}{
+		{name: "enable websocket", param: "websocket", value: "true", wantProxy: entities.Proxy{Websocket: true}},
+		{name: "disable websocket", proxy: entities.Proxy{Websocket: true}, param: "websocket", value: "false"},
+		{name: "invalid websocket", param: "websocket", value: "on", wantErr: true},
+		{name: "websocket for grpc", proxy: grpc, param: "websocket", value: "true", wantErr: true},
+		{name: "https upstream", param: "upstream-protocol", value: "https", wantFullSsl: true},
+		{name: "http upstream", proxy: grpc, fullSsl: true, param: "upstream-protocol", value: "http"},
+		{name: "grpc keeps ssl", fullSsl: true, param: "upstream-protocol", value: "grpc", wantProxy: grpc, wantFullSsl: true},
+		{name: "grpc with websocket", proxy: entities.Proxy{Websocket: true}, param: "upstream-protocol", value: "grpc", wantErr: true},
+		{
+			name:      "h2c for caddy",
+			kind:      webserver.ServerCaddy,
+			fullSsl:   true,
+			param:     "upstream-protocol",
+			value:     "h2c",
+			wantProxy: entities.Proxy{Protocol: webserver.ProtocolH2c},
+		},
+		{name: "h2c for nginx", kind: webserver.ServerNginx, param: "upstream-protocol", value: "h2c", wantErr: true},
+		{name: "unknown protocol", param: "upstream-protocol", value: "ftp", wantErr: true},
+		{name: "timeout", param: "timeout", value: "5m", wantProxy: entities.Proxy{Timeout: "5m"}},
+		{name: "default timeout", proxy: entities.Proxy{Timeout: "5m"}, param: "timeout", value: proxyDefault},
+		{name: "timeout below a second", param: "timeout", value: "500ms", wantErr: true},
+		{name: "invalid timeout", param: "timeout", value: "5", wantErr: true},
+		{name: "max body", param: "max-body", value: "100m", wantProxy: entities.Proxy{MaxBody: "100m"}},
+		{name: "default max body", proxy: entities.Proxy{MaxBody: "100m"}, param: "max-body", value: proxyDefault},
+		{name: "invalid max body", param: "max-body", value: "100mb", wantErr: true},
+		{name: "unknown parameter", param: "retries", value: "3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{Webserver: config.Webserver{Kind: tt.kind}}
+			d := &entities.Domain{Proxy: tt.proxy, FullSsl: tt.fullSsl}
+			err := h.setProxyParam(d, tt.param, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setProxyParam(%q, %q) error = %v, wantErr %v", tt.param, tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if d.Proxy != tt.proxy || d.FullSsl != tt.fullSsl {
+					t.Errorf("setProxyParam(%q, %q) changed the domain on error: %+v, full ssl %v", tt.param, tt.value, d.Proxy, d.FullSsl)
+				}
+				return
+			}
+			if d.Proxy != tt.wantProxy || d.FullSsl != tt.wantFullSsl {
+				t.Errorf("setProxyParam(%q, %q) = %+v, full ssl %v, want %+v, full ssl %v",
+					tt.param, tt.value, d.Proxy, d.FullSsl, tt.wantProxy, tt.wantFullSsl)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/config"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// reserveFqdn keeps the name and the aliases under the parent domain of the deleted domain
+// for its owner during the cooldown, so stale bookmarks and redirect URIs don't lead to
+// another user's machine.
+func (h *Handler) reserveFqdn(d *entities.Domain) {
+	if h.Webserver.ReservationCooldown == "0" {
+		return
+	}
+	cooldown, err := config.ParseDuration(h.Webserver.ReservationCooldown)
+	if err != nil {
+		log.Err(err).Msg(fmt.Sprintf("[bot] error reserving domain name %s", d.FQDN))
+		return
+	}
+	hostnames := []string{d.FQDN}
+	for _, alias := range d.Aliases {
+		if strings.HasSuffix(alias, "."+h.Webserver.ParentDomain) {
+			hostnames = append(hostnames, alias)
+		}
+	}
+	now := time.Now()
+	for _, hostname := range hostnames {
+		reservation := &entities.Reservation{
+			FQDN:      hostname,
+			UserId:    d.UserId,
+			CreatedAt: now,
+			ExpiresAt: now.Add(cooldown),
+		}
+		if err = h.Store.ReservationRepository().Save(reservation); err != nil {
+			log.Err(err).Msg(fmt.Sprintf("[bot] error reserving domain name %s", hostname))
+			continue
+		}
+		log.Info().Msg(fmt.Sprintf("[bot] reserved domain name %s for %s until %s", hostname, d.UserId, reservation.ExpiresAt))
+	}
+}
+
+// checkFqdnReserved returns ErrDomainReserved if the FQDN is reserved for another user.
+func (h *Handler) checkFqdnReserved(userId, fqdn string) error {
+	r, err := h.Store.ReservationRepository().GetByFqdn(fqdn)
+	if errors.Is(err, store.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if r.UserId == userId {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is kept for <@%s> until %s", ErrDomainReserved, r.FQDN, r.UserId, r.ExpiresAt.In(h.Timezone).Format("2006-01-02 15:04"))
+}
+
+// releaseOwnReservation removes the reservation when the previous owner takes the name back.
+func (h *Handler) releaseOwnReservation(fqdn string) {
+	err := h.Store.ReservationRepository().DeleteByFqdn(fqdn)
+	if err != nil && !errors.Is(err, store.ErrNoRowsDeleted) {
+		log.Err(err).Msg(fmt.Sprintf("[bot] error releasing domain name %s", fqdn))
+	}
+}
+
+// DomainReservationList returns the active reservations.
+func (h *Handler) DomainReservationList() ([]*entities.Reservation, error) {
+	return h.Store.ReservationRepository().GetAll()
+}
+
+// DomainReservationRelease makes the reserved name available to everyone.
+func (h *Handler) DomainReservationRelease(fqdn string) error {
+	if err := h.Store.ReservationRepository().DeleteByFqdn(fqdn); err != nil {
+		if errors.Is(err, store.ErrNoRowsDeleted) {
+			return fmt.Errorf("domain name %s is not reserved", fqdn)
+		}
+		return err
+	}
+	log.Info().Msg(fmt.Sprintf("[bot] released domain name %s", fqdn))
+	return nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/rs/zerolog/log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxDomainRoutes = 10
+
+var routePathRegexp = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)+$`)
+
+// DomainRouteAdd adds a route or replaces the existing one with the same path.
+// The target is "ip:port" or just "ip" which means port 80.
+func (h *Handler) DomainRouteAdd(userId, selector, path, target string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	path, err = normalizeRoutePath(path)
+	if err != nil {
+		return "", err
+	}
+	ip, port, err := parseRouteTarget(target)
+	if err != nil {
+		return "", err
+	}
+	if err = h.checkUpstreamIp(userId, userId, d.FQDN, ip, port, port); err != nil {
+		return "", err
+	}
+
+	route := entities.Route{Path: path, IP: ip, Port: port}
+	replaced := false
+	for i, r := range d.Routes {
+		if r.Path == path {
+			d.Routes[i] = route
+			replaced = true
+		}
+	}
+	if !replaced {
+		if len(d.Routes) >= maxDomainRoutes {
+			return "", fmt.Errorf("domain can't have more than %d routes", maxDomainRoutes)
+		}
+		d.Routes = append(d.Routes, route)
+	}
+
+	if err = h.updateNginxConf(d); err != nil {
+		return "", err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return "", err
+	}
+	h.recordRevision(d, userId, fmt.Sprintf("route add %s %s:%s", path, ip, port))
+	log.Info().Msg(fmt.Sprintf("[bot] added route %s -> %s:%s to domain %s", path, ip, port, d.FQDN))
+	result := fmt.Sprintf("Route %s%s -> %s:%s added", d.FQDN, path, ip, port)
+	if warning := h.ipConflictWarning(userId, d.FQDN, ip, port); warning != "" {
+		result += "\n" + warning
+	}
+	return result, nil
+}
+
+// DomainRouteRemove removes the route with the given path.
+func (h *Handler) DomainRouteRemove(userId, selector, path string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	path, err = normalizeRoutePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	var routes []entities.Route
+	for _, r := range d.Routes {
+		if r.Path != path {
+			routes = append(routes, r)
+		}
+	}
+	if len(routes) == len(d.Routes) {
+		return "", fmt.Errorf("route %s not found", path)
+	}
+	d.Routes = routes
+
+	if err = h.updateNginxConf(d); err != nil {
+		return "", err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return "", err
+	}
+	h.recordRevision(d, userId, fmt.Sprintf("route remove %s", path))
+	log.Info().Msg(fmt.Sprintf("[bot] removed route %s from domain %s", path, d.FQDN))
+	return fmt.Sprintf("Route %s%s removed", d.FQDN, path), nil
+}
+
+// DomainRouteList returns a human-readable list of the domain routes.
+func (h *Handler) DomainRouteList(userId, selector string) (string, error) {
+	d, err := h.findUserDomain(userId, selector)
+	if err != nil {
+		return "", err
+	}
+	hasRoot := false
+	var lines []string
+	for _, r := range d.Routes {
+		if r.Path == "/" {
+			hasRoot = true
+		}
+		lines = append(lines, fmt.Sprintf("%s -> %s:%s", r.Path, r.IP, r.Port))
+	}
+	if !hasRoot {
+		lines = append(lines, fmt.Sprintf("/ -> %s:%s (default)", d.IP, d.Port))
+	}
+	return fmt.Sprintf("Routes of %s:\n%s", d.FQDN, strings.Join(lines, "\n")), nil
+}
+
+// normalizeRoutePath validates the route path and strips the trailing slash.
+func normalizeRoutePath(path string) (string, error) {
+	if path == "/" {
+		return path, nil
+	}
+	path = strings.TrimSuffix(path, "/")
+	if !routePathRegexp.MatchString(path) {
+		return "", fmt.Errorf("invalid route path %q. Path must start with / and contain only latin letters, digits and . _ ~ -", path)
+	}
+	return path, nil
+}
+
+func parseRouteTarget(target string) (ip, port string, err error) {
+	if !strings.Contains(target, ":") {
+		return target, "80", nil
+	}
+	ip, port, err = net.SplitHostPort(target)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid route target %q, expected <ip>:<port>", target)
+	}
+	portInt, err := strconv.Atoi(port)
+	if err != nil || portInt < 1 || portInt > 65535 {
+		return "", "", fmt.Errorf("port must be in range 1-65535")
+	}
+	return ip, port, nil
+}
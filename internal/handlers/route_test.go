@@ -0,0 +1,66 @@
+package handlers
+
+import "testing"
+
+func TestNormalizeRoutePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "root", path: "/", want: "/"},
+		{name: "single segment", path: "/api", want: "/api"},
+		{name: "trailing slash", path: "/api/", want: "/api"},
+		{name: "nested path", path: "/api/v1.2/users_list~x", want: "/api/v1.2/users_list~x"},
+		{name: "empty", path: "", wantErr: true},
+		{name: "no leading slash", path: "api", wantErr: true},
+		{name: "double slash", path: "/api//v1", wantErr: true},
+		{name: "space", path: "/my api", wantErr: true},
+		{name: "config syntax", path: "/api;", wantErr: true},
+		{name: "regex symbols", path: "/api/.*", wantErr: true},
+		{name: "query", path: "/api?x=1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeRoutePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeRoutePath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeRoutePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRouteTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		wantIp   string
+		wantPort string
+		wantErr  bool
+	}{
+		{name: "ip without port", target: "10.0.0.5", wantIp: "10.0.0.5", wantPort: "80"},
+		{name: "ip with port", target: "10.0.0.5:8080", wantIp: "10.0.0.5", wantPort: "8080"},
+		{name: "highest port", target: "10.0.0.5:65535", wantIp: "10.0.0.5", wantPort: "65535"},
+		{name: "ipv6 with port", target: "[fd00::5]:8080", wantIp: "fd00::5", wantPort: "8080"},
+		{name: "zero port", target: "10.0.0.5:0", wantErr: true},
+		{name: "port out of range", target: "10.0.0.5:65536", wantErr: true},
+		{name: "port is not a number", target: "10.0.0.5:http", wantErr: true},
+		{name: "empty port", target: "10.0.0.5:", wantErr: true},
+		{name: "too many colons", target: "10.0.0.5:80:81", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip, port, err := parseRouteTarget(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRouteTarget(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+			if ip != tt.wantIp || port != tt.wantPort {
+				t.Errorf("parseRouteTarget(%q) = %q, %q, want %q, %q", tt.target, ip, port, tt.wantIp, tt.wantPort)
+			}
+		})
+	}
+}
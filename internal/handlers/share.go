@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/rs/zerolog/log"
+)
+
+// DomainShare adds a co-owner to the domain. Co-owners can update the domain and
+// receive expiry reminders, but only the owner can share or delete it.
+func (h *Handler) DomainShare(userId, selector, coOwnerId string) (*entities.Domain, error) {
+	d, err := h.findOwnDomain(userId, selector)
+	if err != nil {
+		return nil, err
+	}
+	if d.IsOwner(coOwnerId) {
+		return nil, fmt.Errorf("<@%s> already owns %s", coOwnerId, d.FQDN)
+	}
+	d.CoOwners = append(d.CoOwners, coOwnerId)
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return nil, err
+	}
+	h.recordRevision(d, userId, fmt.Sprintf("share <@%s>", coOwnerId))
+	log.Info().Msg(fmt.Sprintf("[bot] shared domain %s with %s", d.FQDN, coOwnerId))
+	return d, nil
+}
+
+// DomainUnshare removes a co-owner from the domain.
+func (h *Handler) DomainUnshare(userId, selector, coOwnerId string) (*entities.Domain, error) {
+	d, err := h.findOwnDomain(userId, selector)
+	if err != nil {
+		return nil, err
+	}
+	var coOwners []string
+	for _, id := range d.CoOwners {
+		if id != coOwnerId {
+			coOwners = append(coOwners, id)
+		}
+	}
+	if len(coOwners) == len(d.CoOwners) {
+		return nil, fmt.Errorf("<@%s> is not a co-owner of %s", coOwnerId, d.FQDN)
+	}
+	d.CoOwners = coOwners
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return nil, err
+	}
+	h.recordRevision(d, userId, fmt.Sprintf("unshare <@%s>", coOwnerId))
+	log.Info().Msg(fmt.Sprintf("[bot] unshared domain %s with %s", d.FQDN, coOwnerId))
+	return d, nil
+}
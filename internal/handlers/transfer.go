@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// IsAdmin reports whether the user is a bot admin.
+func (h *Handler) IsAdmin(userId string) bool {
+	for _, id := range h.AdminUserIDs {
+		if id == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// DomainTransfer makes another user the owner of the domain. Only admins can transfer a domain.
+// The previous owner loses access unless they are a co-owner. Basic auth credentials are
+// regenerated, the new ones are set in BasicAuthPassword.
+func (h *Handler) DomainTransfer(userId, fqdn, newOwnerId string, newOwnerNames []string) (d *entities.Domain, previousOwnerId string, err error) {
+	if !h.IsAdmin(userId) {
+		return nil, "", fmt.Errorf("only admins can transfer domains")
+	}
+	d, err = h.Store.DomainRepository().GetByFqdn(fqdn)
+	if err != nil {
+		if errors.Is(err, store.ErrRecordNotFound) {
+			return nil, "", ErrDomainNotFound
+		}
+		return nil, "", err
+	}
+	if d.UserId == newOwnerId {
+		return nil, "", fmt.Errorf("<@%s> already owns %s", newOwnerId, d.FQDN)
+	}
+
+	previousOwnerId = d.UserId
+	d.UserId = newOwnerId
+	d.UserName = firstNonEmpty(newOwnerNames)
+	var coOwners []string
+	for _, id := range d.CoOwners {
+		if id != newOwnerId {
+			coOwners = append(coOwners, id)
+		}
+	}
+	d.CoOwners = coOwners
+	// the previous owner knows the password, new credentials are generated when the config is created
+	d.BasicAuthUser, d.BasicAuthHash = "", ""
+
+	// the holding page of a paused domain names the owner
+	if err = h.updateNginxConf(d); err != nil {
+		return nil, "", err
+	}
+	if err = h.Store.DomainRepository().Update(d); err != nil {
+		return nil, "", err
+	}
+	h.recordRevision(d, userId, fmt.Sprintf("transfer <@%s> -> <@%s>", previousOwnerId, newOwnerId))
+	log.Info().Msg(fmt.Sprintf("[bot] domain %s transferred from %s to %s by %s", d.FQDN, previousOwnerId, newOwnerId, userId))
+	return d, previousOwnerId, nil
+}
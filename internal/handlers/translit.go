@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ukrainianTable follows the official Ukrainian transliteration (KMU resolution No. 55, 2010).
+// Letters with two values use the first one at the beginning of a word.
+var ukrainianTable = map[rune][2]string{
+	'а': {"a", "a"}, 'б': {"b", "b"}, 'в': {"v", "v"}, 'г': {"h", "h"}, 'ґ': {"g", "g"},
+	'д': {"d", "d"}, 'е': {"e", "e"}, 'є': {"ye", "ie"}, 'ж': {"zh", "zh"}, 'з': {"z", "z"},
+	'и': {"y", "y"}, 'і': {"i", "i"}, 'ї': {"yi", "i"}, 'й': {"y", "i"}, 'к': {"k", "k"},
+	'л': {"l", "l"}, 'м': {"m", "m"}, 'н': {"n", "n"}, 'о': {"o", "o"}, 'п': {"p", "p"},
+	'р': {"r", "r"}, 'с': {"s", "s"}, 'т': {"t", "t"}, 'у': {"u", "u"}, 'ф': {"f", "f"},
+	'х': {"kh", "kh"}, 'ц': {"ts", "ts"}, 'ч': {"ch", "ch"}, 'ш': {"sh", "sh"}, 'щ': {"shch", "shch"},
+	'ь': {"", ""}, 'ю': {"yu", "iu"}, 'я': {"ya", "ia"},
+}
+
+// russianTable follows the ICAO Doc 9303 table used for Russian passports since 2013.
+var russianTable = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia",
+}
+
+// apostrophes are dropped by both tables
+var apostrophes = "'’ʼ`"
+
+// transliterate converts Ukrainian or Russian Cyrillic text to lower case latin.
+// Russian is used only when the text has letters that don't exist in Ukrainian.
+// Other non-latin symbols are kept as is.
+func transliterate(s string) string {
+	s = strings.ToLower(s)
+	russian := strings.ContainsAny(s, "ёыэъ") && !strings.ContainsAny(s, "іїєґ")
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if strings.ContainsRune(apostrophes, r) {
+			continue
+		}
+		if russian {
+			if v, ok := russianTable[r]; ok {
+				b.WriteString(v)
+				continue
+			}
+		} else if v, ok := ukrainianTable[r]; ok {
+			// "зг" is transliterated as "zgh" to distinguish it from "ж"
+			if r == 'г' && i > 0 && runes[i-1] == 'з' {
+				b.WriteString("gh")
+				continue
+			}
+			if isWordStart(runes, i) {
+				b.WriteString(v[0])
+			} else {
+				b.WriteString(v[1])
+			}
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isWordStart reports whether the rune at i starts a word, apostrophes are part of a word.
+func isWordStart(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := runes[i-1]
+	return !unicode.IsLetter(prev) && !strings.ContainsRune(apostrophes, prev)
+}
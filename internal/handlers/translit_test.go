@@ -0,0 +1,31 @@
+package handlers
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "latin is kept", in: "Jane Doe", want: "jane doe"},
+		{name: "ukrainian", in: "Олександр Шевченко", want: "oleksandr shevchenko"},
+		{name: "ukrainian word start letters", in: "Юлія Євтушенко", want: "yuliia yevtushenko"},
+		{name: "ukrainian yi and i", in: "Їжак Йосипович", want: "yizhak yosypovych"},
+		{name: "ukrainian zgh", in: "Згурський", want: "zghurskyi"},
+		{name: "ukrainian apostrophe and soft sign", in: "Мар'яна Гладь", want: "mariana hlad"},
+		{name: "ukrainian g", in: "Ґудзь", want: "gudz"},
+		{name: "russian", in: "Фёдор Щербаков", want: "fedor shcherbakov"},
+		{name: "russian hard sign", in: "Подъячев Эдуард", want: "podieiachev eduard"},
+		{name: "ambiguous text uses ukrainian", in: "Григорій Гирич", want: "hryhorii hyrych"},
+		{name: "emoji is kept", in: "Ганна 🚀", want: "hanna 🚀"},
+		{name: "empty", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transliterate(tt.in); got != tt.want {
+				t.Errorf("transliterate(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,21 +1,119 @@
 package handlers
 
 import (
+	"fmt"
 	"github.com/marstr/guid"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
-func transformName(name string) string {
-	if len(name) == 0 {
-		return getRandomString()
+const maxGeneratedLabelLength = 40
+
+var notAllowedSymbols = regexp.MustCompile("[^a-z0-9]+")
+
+// transformName builds the subdomain label from the first of the names that contains
+// latin letters or digits after transliteration, e.g. Slack display name, real name and handle.
+// If none of them fits, a random label is returned.
+func transformName(names ...string) string {
+	for _, name := range names {
+		if label := nameToLabel(name); label != "" {
+			return label
+		}
+	}
+	return getRandomString()
+}
+
+// nameToLabel returns the first letter of the first name and the last name joined by a dash,
+// e.g. "Jane Doe" becomes "j-doe". A single word is used as is.
+func nameToLabel(name string) string {
+	var words []string
+	for _, w := range strings.FieldsFunc(transliterate(name), split) {
+		w = strings.Trim(notAllowedSymbols.ReplaceAllString(w, "-"), "-")
+		if w != "" {
+			words = append(words, w)
+		}
+	}
+	var label string
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		label = words[0]
+	default:
+		label = words[0][:1] + "-" + words[1]
+	}
+	if len(label) > maxGeneratedLabelLength {
+		label = strings.TrimRight(label[:maxGeneratedLabelLength], "-")
+	}
+	return label
+}
+
+const maxLabelLength = 63
+
+// domainNameRegexp matches a DNS label without leading, trailing or doubled dashes
+var domainNameRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reservedLabels can't be used as custom subdomain labels
+var reservedLabels = []string{
+	"www", "api", "admin", "administrator", "root", "mail", "smtp", "imap", "pop", "ftp",
+	"ns", "ns1", "ns2", "dns", "vpn", "proxy", "static", "cdn", "status", "login", "auth",
+}
+
+// validateLabel checks a custom subdomain label against DNS label rules and reserved words.
+func validateLabel(label string) error {
+	if len(label) < 3 || len(label) > maxLabelLength {
+		return ErrInvalidLabelLength
+	}
+	if !domainNameRegexp.MatchString(label) {
+		return ErrInvalidDomainName
+	}
+	for _, r := range reservedLabels {
+		if r == label {
+			return ErrReservedName
+		}
+	}
+	return nil
+}
+
+// generatedLabel appends the domain name to the generated label. The generated part is
+// trimmed so the label with the longest collision suffix of freeFqdn stays a valid DNS label.
+func generatedLabel(generated, name string) string {
+	suffix := fmt.Sprintf("-%d", maxLabelSuffix)
+	if name != "" {
+		suffix = "-" + name + suffix
+	}
+	if max := maxLabelLength - len(suffix); len(generated) > max {
+		generated = strings.TrimRight(generated[:max], "-")
+	}
+	if name != "" {
+		return generated + "-" + name
+	}
+	return generated
+}
+
+// validateDomainName checks the optional name used to create additional domains.
+// The name must not clash with DomainUpdate parameters as both share one command.
+func validateDomainName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if len(name) > 30 || !domainNameRegexp.MatchString(name) {
+		return ErrInvalidDomainName
+	}
+	if IsDomainUpdateParam(name) {
+		return ErrReservedName
+	}
+	return nil
+}
+
+func firstNonEmpty(values []string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
 	}
-	nameArr := strings.FieldsFunc(name, split)
-	firstName := nameArr[0]
-	lastName := nameArr[1]
-	allowedSymbols, _ := regexp.Compile("[^A-Za-z0-9]+")
-	subdomain := string(firstName[0]) + "-" + lastName
-	return allowedSymbols.ReplaceAllString(strings.ToLower(subdomain), "-")
+	return ""
 }
 
 func getRandomString() string {
@@ -23,5 +121,5 @@ func getRandomString() string {
 }
 
 func split(r rune) bool {
-	return r == ' ' || r == '.'
+	return unicode.IsSpace(r) || r == '.'
 }
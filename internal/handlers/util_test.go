@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTransformName(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  string
+	}{
+		{name: "first and last name", names: []string{"Jane Doe"}, want: "j-doe"},
+		{name: "dot separated", names: []string{"jane.doe"}, want: "j-doe"},
+		{name: "cyrillic display name", names: []string{"Тарас Шевченко"}, want: "t-shevchenko"},
+		{name: "single word", names: []string{"Jane"}, want: "jane"},
+		{name: "single cyrillic word", names: []string{"Богдан"}, want: "bohdan"},
+		{name: "emoji is dropped", names: []string{"Jane 🚀 Doe"}, want: "j-doe"},
+		{name: "symbols inside the word", names: []string{"Jane O'Neil-Smith"}, want: "j-oneil-smith"},
+		{name: "fallback to real name", names: []string{"🚀🚀", "Jane Doe", "jdoe"}, want: "j-doe"},
+		{name: "fallback to handle", names: []string{"", "", "jdoe"}, want: "jdoe"},
+		{name: "extra spaces", names: []string{"  Jane   Doe  "}, want: "j-doe"},
+		{
+			name:  "long name is truncated",
+			names: []string{"Jane Wolfeschlegelsteinhausenbergerdorffwelchevoralternwarengewissenhaft"},
+			want:  "j-wolfeschlegelsteinhausenbergerdorffwel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transformName(tt.names...); got != tt.want {
+				t.Errorf("transformName(%q) = %q, want %q", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformNameRandomFallback(t *testing.T) {
+	for _, names := range [][]string{nil, {""}, {"🚀", "---", ""}} {
+		got := transformName(names...)
+		if got == "" || strings.Trim(got, "-") != got {
+			t.Errorf("transformName(%q) = %q, want random label", names, got)
+		}
+	}
+}
+
+func TestNameToLabelLength(t *testing.T) {
+	got := nameToLabel(strings.Repeat("a", 39) + "-b")
+	if len(got) > maxGeneratedLabelLength || strings.HasSuffix(got, "-") {
+		t.Errorf("nameToLabel() = %q, want at most %d symbols without trailing dash", got, maxGeneratedLabelLength)
+	}
+}
+
+func TestGeneratedLabel(t *testing.T) {
+	long := strings.Repeat("a", maxGeneratedLabelLength)
+	tests := []struct {
+		name      string
+		generated string
+		domain    string
+		want      string
+	}{
+		{name: "short label", generated: "j-doe", want: "j-doe"},
+		{name: "short label with name", generated: "j-doe", domain: "api", want: "j-doe-api"},
+		{name: "long label", generated: long, want: long},
+		{name: "long label with name", generated: long, domain: "api", want: long + "-api"},
+		{
+			name:      "long label with long name is trimmed",
+			generated: long,
+			domain:    strings.Repeat("b", 30),
+			want:      strings.Repeat("a", 29) + "-" + strings.Repeat("b", 30),
+		},
+		{
+			name:      "trimmed label has no trailing dash",
+			generated: strings.Repeat("a", 28) + "-bbbbbbbbbbb",
+			domain:    strings.Repeat("c", 30),
+			want:      strings.Repeat("a", 28) + "-" + strings.Repeat("c", 30),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generatedLabel(tt.generated, tt.domain)
+			if got != tt.want {
+				t.Errorf("generatedLabel(%q, %q) = %q, want %q", tt.generated, tt.domain, got, tt.want)
+			}
+			if withSuffix := fmt.Sprintf("%s-%d", got, maxLabelSuffix); validateLabel(withSuffix) != nil {
+				t.Errorf("generatedLabel(%q, %q) with suffix %q is not a valid label", tt.generated, tt.domain, withSuffix)
+			}
+		})
+	}
+}
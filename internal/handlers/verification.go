@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/config"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/1k-off/dev-helper-bot/internal/webserver"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ipVerificationTokenLifetime = time.Hour
+	ipVerificationTimeout       = 5 * time.Second
+	ipVerificationPath          = "/.well-known/dev-helper/"
+)
+
+// checkIpVerified returns nil when the user may map domains to the IP. Otherwise it issues
+// a token and returns an error that explains how to verify the IP.
+func (h *Handler) checkIpVerified(userId, ip, port string) error {
+	if !h.Webserver.IpVerification.Enabled || h.IsAdmin(userId) || ipExempt(h.Webserver.IpVerification.ExemptSubnets, ip) {
+		return nil
+	}
+	v, err := h.Store.VerificationRepository().Get(userId, ip)
+	if err != nil && !errors.Is(err, store.ErrRecordNotFound) {
+		return err
+	}
+	if v != nil && v.Verified {
+		return nil
+	}
+	if v == nil {
+		if v, err = h.newIpVerification(userId, ip); err != nil {
+			return err
+		}
+	}
+	if port == "" {
+		port = "80"
+	}
+	return fmt.Errorf("%w. Serve the text `%s` at %s over plain HTTP and run `domain verify %s`, then repeat the command. The token is valid for %s",
+		ErrIpNotVerified, v.Token, ipVerificationUrl(ip, port, v.Token), net.JoinHostPort(ip, port), ipVerificationTokenLifetime)
+}
+
+func (h *Handler) newIpVerification(userId, ip string) (*entities.IpVerification, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	v := &entities.IpVerification{
+		UserId:    userId,
+		IP:        ip,
+		Token:     hex.EncodeToString(b),
+		ExpiresAt: time.Now().Add(ipVerificationTokenLifetime),
+	}
+	if err := h.Store.VerificationRepository().Save(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// DomainVerifyIp fetches the token issued to the user from the target, "ip:port" or just "ip"
+// which means port 80, and marks the IP as verified when the token matches.
+func (h *Handler) DomainVerifyIp(userId, target string) (string, error) {
+	ip, port, err := parseRouteTarget(target)
+	if err != nil {
+		return "", err
+	}
+	if err = webserver.CheckIfIpAllowed(h.Webserver.AllowedSubnets, h.Webserver.DeniedIPs, ip); err != nil {
+		return "", err
+	}
+	v, err := h.Store.VerificationRepository().Get(userId, ip)
+	if errors.Is(err, store.ErrRecordNotFound) {
+		return "", fmt.Errorf("there is no token for %s, run the domain command with this IP to get one", ip)
+	}
+	if err != nil {
+		return "", err
+	}
+	if v.Verified {
+		return fmt.Sprintf("%s is already verified", ip), nil
+	}
+
+	url := ipVerificationUrl(ip, port, v.Token)
+	body, err := fetchIpVerificationToken(url)
+	if err != nil {
+		return "", fmt.Errorf("can't fetch %s: %v", url, err)
+	}
+	if body != v.Token {
+		return "", fmt.Errorf("%s doesn't return the token `%s`", url, v.Token)
+	}
+
+	validFor, err := config.ParseDuration(h.Webserver.IpVerification.ValidFor)
+	if err != nil {
+		return "", err
+	}
+	v.Verified = true
+	v.ExpiresAt = time.Now().Add(validFor)
+	if err = h.Store.VerificationRepository().Save(v); err != nil {
+		return "", err
+	}
+	log.Info().Msg(fmt.Sprintf("[bot] ip %s is verified by %s", ip, userId))
+	return fmt.Sprintf("%s is verified until %s, repeat the domain command now", ip, v.ExpiresAt.In(h.Timezone).Format("02.01.2006")), nil
+}
+
+func ipVerificationUrl(ip, port, token string) string {
+	return "http://" + net.JoinHostPort(ip, port) + ipVerificationPath + token
+}
+
+// fetchIpVerificationToken returns the trimmed response body. Redirects are not followed,
+// so the token has to be served by the IP itself.
+func fetchIpVerificationToken(url string) (string, error) {
+	client := &http.Client{
+		Timeout: ipVerificationTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func ipExempt(subnets []string, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, s := range subnets {
+		if _, network, err := net.ParseCIDR(s); err == nil && network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
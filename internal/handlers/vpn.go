@@ -137,7 +137,7 @@ func (h *Handler) VPNEUDeactivateExpired() error {
 		log.Info().Msg(fmt.Sprintf("[bot] deactivated user %v", a))
 	}
 	if len(errors) > 0 {
-		return fmt.Errorf(fmt.Sprintf("one or more errors occured while deactivating users: %v", errors))
+		return fmt.Errorf("one or more errors occured while deactivating users: %v", errors)
 	}
 	return nil
 }
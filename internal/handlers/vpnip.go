@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/webserver"
+	"github.com/rs/zerolog/log"
+)
+
+// VpnAddresses returns VPN addresses of connected users by their emails.
+func (h *Handler) VpnAddresses() (map[string]string, error) {
+	return h.PritunlClient.GetVirtualAddresses()
+}
+
+// DomainsFollowingVpn returns domains whose IP follows the VPN address of the owner.
+func (h *Handler) DomainsFollowingVpn() ([]*entities.Domain, error) {
+	domains, err := h.Store.DomainRepository().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var result []*entities.Domain
+	for _, d := range domains {
+		if d.IpMode == entities.IpModeVpn {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// DomainSyncVpnIp points the domain to the VPN address of its owner. It returns the previous
+// IP when the IP is changed and an empty string when the domain already uses the address.
+// The address comes from the VPN server, so it is not verified, but it is checked for conflicts.
+func (h *Handler) DomainSyncVpnIp(d *entities.Domain, address string) (string, error) {
+	if address == d.IP {
+		return "", nil
+	}
+	if err := webserver.CheckIfIpAllowed(h.Webserver.AllowedSubnets, h.Webserver.DeniedIPs, address); err != nil {
+		return "", err
+	}
+	if err := h.checkIpConflict(d.UserId, d.FQDN, address, upstreamPort(d)); err != nil {
+		return "", err
+	}
+	previous := d.IP
+	d.IP = address
+	if err := h.updateNginxConf(d); err != nil {
+		return "", err
+	}
+	if err := h.Store.DomainRepository().Update(d); err != nil {
+		return "", err
+	}
+	h.recordRevision(d, d.UserId, "vpn address "+address)
+	log.Info().Msg(fmt.Sprintf("[bot] domain %s follows vpn address of %s: %s -> %s", d.FQDN, d.UserId, previous, address))
+	return previous, nil
+}
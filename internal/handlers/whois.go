@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/rs/zerolog/log"
+	"net"
+	"strings"
+)
+
+const whoisHistoryLimit = 10
+
+// Whois returns owners and recent changes of the domains using the IP, or of the domain
+// with the hostname as its fqdn or alias. For a hostname no domain uses anymore, it reports the
+// last domain which used it from the history.
+func (h *Handler) Whois(query string) (string, error) {
+	query = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(query)), ".")
+	if query == "" {
+		return "", fmt.Errorf("specify an IP or a hostname")
+	}
+	if net.ParseIP(query) != nil {
+		return h.whoisIp(query)
+	}
+	return h.whoisHostname(query)
+}
+
+func (h *Handler) whoisIp(ip string) (string, error) {
+	domains, err := h.Store.DomainRepository().GetAllByIp(ip)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	if len(domains) == 0 {
+		lines = append(lines, fmt.Sprintf("No domains point to %s", ip))
+	} else {
+		lines = append(lines, fmt.Sprintf("Domains on %s:", ip))
+	}
+	for _, d := range domains {
+		if d.IP == ip {
+			lines = append(lines, fmt.Sprintf("%s → %s, %s", d.FQDN, net.JoinHostPort(d.IP, upstreamPort(d)), whoisOwners(d)))
+		}
+		for _, r := range d.Routes {
+			if r.IP == ip {
+				lines = append(lines, fmt.Sprintf("%s%s → %s, %s", d.FQDN, r.Path, net.JoinHostPort(r.IP, r.Port), whoisOwners(d)))
+			}
+		}
+	}
+
+	addresses, err := h.VpnAddresses()
+	if err != nil {
+		log.Err(err).Msg("[bot] error getting vpn addresses")
+	}
+	for email, address := range addresses {
+		if address == ip {
+			lines = append(lines, fmt.Sprintf("VPN address of %s", email))
+		}
+	}
+
+	revisions, err := h.Store.HistoryRepository().GetAllByIp(ip, whoisHistoryLimit)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(lines, h.whoisHistory(revisions)...), "\n"), nil
+}
+
+func (h *Handler) whoisHostname(hostname string) (string, error) {
+	d, err := h.Store.DomainRepository().GetByHostname(hostname)
+	if err != nil {
+		if errors.Is(err, store.ErrRecordNotFound) {
+			return h.whoisDeletedHostname(hostname)
+		}
+		return "", err
+	}
+	lines := []string{fmt.Sprintf("%s → %s, %s", d.FQDN, net.JoinHostPort(d.IP, upstreamPort(d)), whoisOwners(d))}
+	if d.FQDN != hostname {
+		lines[0] = hostname + " is an alias of " + lines[0]
+	}
+	lines = append(lines, fmt.Sprintf("Created %s, deletes %s",
+		d.CreatedAt.In(h.Timezone).Format("2006-01-02"), d.DeleteAt.In(h.Timezone).Format("2006-01-02")))
+
+	revisions, err := h.Store.HistoryRepository().GetAllByDomainId(d.Id, whoisHistoryLimit)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(lines, h.whoisHistory(revisions)...), "\n"), nil
+}
+
+// whoisDeletedHostname reports the last known domain of a hostname no domain uses anymore.
+func (h *Handler) whoisDeletedHostname(hostname string) (string, error) {
+	rev, err := h.Store.HistoryRepository().GetLastByHostname(hostname)
+	if err != nil {
+		if errors.Is(err, store.ErrRecordNotFound) {
+			return "", fmt.Errorf("no domain uses %s", hostname)
+		}
+		return "", err
+	}
+	d := &rev.Domain
+	lines := []string{
+		fmt.Sprintf("No domain uses %s now, it was last used by:", hostname),
+		fmt.Sprintf("%s → %s, %s", d.FQDN, net.JoinHostPort(d.IP, upstreamPort(d)), whoisOwners(d)),
+	}
+	if d.FQDN != hostname {
+		lines[1] = hostname + " as an alias of " + lines[1]
+	}
+	lines = append(lines, fmt.Sprintf("Last change %s <@%s>: %s",
+		rev.CreatedAt.In(h.Timezone).Format("2006-01-02 15:04"), rev.Actor, rev.Action))
+
+	revisions, err := h.Store.HistoryRepository().GetAllByDomainId(d.Id, whoisHistoryLimit)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(lines, h.whoisHistory(revisions)...), "\n"), nil
+}
+
+func whoisOwners(d *entities.Domain) string {
+	owners := fmt.Sprintf("owner <@%s>", d.UserId)
+	for i, id := range d.CoOwners {
+		if i == 0 {
+			owners += ", co-owners"
+		}
+		owners += fmt.Sprintf(" <@%s>", id)
+	}
+	return owners
+}
+
+func (h *Handler) whoisHistory(revisions []*entities.Revision) []string {
+	if len(revisions) == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("Recent changes (last %d):", whoisHistoryLimit)}
+	for _, r := range revisions {
+		lines = append(lines, fmt.Sprintf("%s #%d %s <@%s>: %s, IP %s",
+			r.FQDN, r.Rev, r.CreatedAt.In(h.Timezone).Format("2006-01-02 15:04"), r.Actor, r.Action, r.Domain.IP))
+	}
+	return lines
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"net"
+	"strings"
+	"time"
+)
+
+const wildcardDnsTimeout = 5 * time.Second
+
+// checkWildcardSupport makes sure that subdomains of the domain reach the webserver and
+// can be served over TLS, so the wildcard config doesn't produce broken sites.
+func (h *Handler) checkWildcardSupport(d *entities.Domain) error {
+	if !h.Webserver.WildcardTls {
+		return fmt.Errorf("%w: the webserver can't get TLS certificates for *.%s, ask an admin to set up wildcard TLS for %s", ErrNoWildcardSupport, d.FQDN, h.Webserver.ParentDomain)
+	}
+	if hostHeaderSet(d) {
+		return fmt.Errorf("wildcard domains pass the original Host header to the upstream, unset the custom Host header first")
+	}
+
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	probe := fmt.Sprintf("wildcard-check-%s.%s", hex.EncodeToString(b), d.FQDN)
+	ctx, cancel := context.WithTimeout(context.Background(), wildcardDnsTimeout)
+	defer cancel()
+	want, err := net.DefaultResolver.LookupHost(ctx, d.FQDN)
+	if err != nil {
+		return fmt.Errorf("%w: can't resolve %s: %v", ErrNoWildcardSupport, d.FQDN, err)
+	}
+	got, err := net.DefaultResolver.LookupHost(ctx, probe)
+	if err != nil {
+		return fmt.Errorf("%w: subdomains of %s don't resolve, the DNS zone of %s needs a wildcard record", ErrNoWildcardSupport, d.FQDN, h.Webserver.ParentDomain)
+	}
+	for _, addr := range got {
+		for _, w := range want {
+			if addr == w {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: subdomains of %s resolve to %s instead of %s", ErrNoWildcardSupport, d.FQDN, strings.Join(got, ", "), strings.Join(want, ", "))
+}
+
+// hostHeaderSet reports whether the domain overrides the Host header sent to the upstream.
+func hostHeaderSet(d *entities.Domain) bool {
+	for _, hd := range d.Headers {
+		if hd.Kind == entities.HeaderRequest && hd.Name == "Host" {
+			return true
+		}
+	}
+	return false
+}
@@ -1,18 +1,60 @@
 package store
 
 const (
-	DomainCollection = "web_server"
-	VpnEUCollection  = "vpn_eu"
+	DomainCollection       = "web_server"
+	VpnEUCollection        = "vpn_eu"
+	ReservationCollection  = "reservation"
+	HistoryCollection      = "domain_history"
+	VerificationCollection = "ip_verification"
 )
 
 const (
 	DomainUserIdKey    = "user_id"
+	DomainUserNameKey  = "user_name"
 	DomainIpKey        = "ip"
 	DomainBasicAuthKey = "basic_auth"
+	DomainAuthUserKey  = "basic_auth_user"
+	DomainAuthHashKey  = "basic_auth_hash"
 	DomainFullSslKey   = "full_ssl"
 	DomainDeleteAtKey  = "delete_at"
+	DomainCreatedAtKey = "created_at"
 	DomainFqdnKey      = "fqdn"
+	DomainNameKey      = "name"
 	DomainPortKey      = "port"
+	DomainRoutesKey    = "routes"
+	DomainRouteIpKey   = "routes.ip"
+	DomainCoOwnersKey  = "co_owners"
+	DomainAccessKey    = "access"
+	DomainHealthKey    = "health"
+	DomainStateKey     = "state"
+	DomainPresetKey    = "preset"
+	DomainProxyKey     = "proxy"
+	DomainHeadersKey   = "headers"
+	DomainLogsKey      = "logs"
+	DomainWildcardKey  = "wildcard"
+	DomainAliasesKey   = "aliases"
+	DomainIpModeKey    = "ip_mode"
+)
+
+const (
+	ReservationFqdnKey      = "fqdn"
+	ReservationExpiresAtKey = "expires_at"
+)
+
+const (
+	VerificationUserIdKey    = "user_id"
+	VerificationIpKey        = "ip"
+	VerificationExpiresAtKey = "expires_at"
+)
+
+const (
+	HistoryDomainIdKey = "domain._id"
+	HistoryRevKey      = "rev"
+	HistoryFqdnKey     = "fqdn"
+	HistoryAliasesKey  = "domain.aliases"
+	HistoryIpKey       = "domain.ip"
+	HistoryRouteIpKey  = "domain.routes.ip"
+	HistoryTimeKey     = "created_at"
 )
 
 const (
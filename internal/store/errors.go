@@ -6,4 +6,5 @@ var (
 	ErrRecordNotFound = errors.New("record not found")
 	ErrNoRowsUpdated  = errors.New("no rows updated")
 	ErrNoRowsDeleted  = errors.New("no records deleted")
+	ErrDuplicate      = errors.New("duplicate record")
 )
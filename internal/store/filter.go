@@ -0,0 +1,22 @@
+package store
+
+import (
+	"net"
+	"time"
+)
+
+// DomainFilter selects domains listed by DomainRepository.List. Zero values don't filter.
+type DomainFilter struct {
+	// UserId matches the primary owner
+	UserId string
+	// Subnet matches domains with the upstream IP in the network
+	Subnet        *net.IPNet
+	ExpiresBefore time.Time
+	BasicAuth     *bool
+	FullSsl       *bool
+	// SortBy is a domain key like DomainDeleteAtKey, domains are sorted by fqdn by default
+	SortBy string
+	Offset int64
+	// Limit of zero returns all matching domains
+	Limit int64
+}
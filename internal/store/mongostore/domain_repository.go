@@ -1,7 +1,9 @@
 package mongostore
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/1k-off/dev-helper-bot/internal/entities"
 	"github.com/1k-off/dev-helper-bot/internal/store"
@@ -10,6 +12,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"net"
+	"sort"
 	"time"
 )
 
@@ -31,31 +35,228 @@ func (r *domainRepository) Create(domain *entities.Domain) error {
 	log.Debug().Msg(fmt.Sprintf("[database] created record: %v", domain))
 	return nil
 }
-func (r *domainRepository) Get(userId string) (domain *entities.Domain, err error) {
-	filter := bson.M{store.DomainUserIdKey: userId}
+
+func (r *domainRepository) GetByFqdn(fqdn string) (domain *entities.Domain, err error) {
+	filter := bson.M{store.DomainFqdnKey: fqdn}
+	result := r.collection.FindOne(r.store.ctx, filter)
+	err = result.Decode(&domain)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return domain, nil
+}
+
+func (r *domainRepository) GetByHostname(hostname string) (domain *entities.Domain, err error) {
+	filter := bson.M{"$or": []bson.M{
+		{store.DomainFqdnKey: hostname},
+		{store.DomainAliasesKey: hostname},
+	}}
 	result := r.collection.FindOne(r.store.ctx, filter)
 	err = result.Decode(&domain)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, store.ErrRecordNotFound
+		}
 		return nil, err
 	}
 	return domain, nil
 }
+
+func (r *domainRepository) GetAllByUserId(userId string) (domains []*entities.Domain, err error) {
+	opts := options.Find().SetSort(bson.D{{Key: store.DomainFqdnKey, Value: 1}})
+	filter := bson.M{"$or": []bson.M{
+		{store.DomainUserIdKey: userId},
+		{store.DomainCoOwnersKey: userId},
+	}}
+	result, err := r.collection.Find(r.store.ctx, filter, opts)
+	if err != nil {
+		log.Error().Err(err)
+		log.Debug().Msg("[database] error when trying to find records by user id")
+		return nil, err
+	}
+	defer func(result *mongo.Cursor, ctx context.Context) {
+		err := result.Close(ctx)
+		if err != nil {
+			log.Error().Err(err)
+			log.Debug().Msg("[database] error when trying to close cursor")
+		}
+	}(result, r.store.ctx)
+	for result.Next(r.store.ctx) {
+		var d *entities.Domain
+		_ = result.Decode(&d)
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+func (r *domainRepository) GetAllByIp(ip string) (domains []*entities.Domain, err error) {
+	opts := options.Find().SetSort(bson.D{{Key: store.DomainFqdnKey, Value: 1}})
+	filter := bson.M{"$or": []bson.M{
+		{store.DomainIpKey: ip},
+		{store.DomainRouteIpKey: ip},
+	}}
+	result, err := r.collection.Find(r.store.ctx, filter, opts)
+	if err != nil {
+		log.Error().Err(err)
+		log.Debug().Msg("[database] error when trying to find records by ip")
+		return nil, err
+	}
+	defer func(result *mongo.Cursor, ctx context.Context) {
+		err := result.Close(ctx)
+		if err != nil {
+			log.Error().Err(err)
+			log.Debug().Msg("[database] error when trying to close cursor")
+		}
+	}(result, r.store.ctx)
+	for result.Next(r.store.ctx) {
+		var d *entities.Domain
+		_ = result.Decode(&d)
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+func (r *domainRepository) GetAll() (domains []*entities.Domain, err error) {
+	opts := options.Find().SetSort(bson.D{{Key: store.DomainFqdnKey, Value: 1}})
+	result, err := r.collection.Find(r.store.ctx, bson.M{}, opts)
+	if err != nil {
+		log.Error().Err(err)
+		log.Debug().Msg("[database] error when trying to find all records")
+		return nil, err
+	}
+	defer func(result *mongo.Cursor, ctx context.Context) {
+		err := result.Close(ctx)
+		if err != nil {
+			log.Error().Err(err)
+			log.Debug().Msg("[database] error when trying to close cursor")
+		}
+	}(result, r.store.ctx)
+	for result.Next(r.store.ctx) {
+		var d *entities.Domain
+		_ = result.Decode(&d)
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+func (r *domainRepository) List(filter store.DomainFilter) (domains []*entities.Domain, total int64, err error) {
+	query := bson.M{}
+	if filter.UserId != "" {
+		query[store.DomainUserIdKey] = filter.UserId
+	}
+	if !filter.ExpiresBefore.IsZero() {
+		query[store.DomainDeleteAtKey] = bson.M{"$lt": primitive.NewDateTimeFromTime(filter.ExpiresBefore)}
+	}
+	if filter.BasicAuth != nil {
+		query[store.DomainBasicAuthKey] = *filter.BasicAuth
+	}
+	if filter.FullSsl != nil {
+		query[store.DomainFullSslKey] = *filter.FullSsl
+	}
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = store.DomainFqdnKey
+	}
+	opts := options.Find().SetSort(bson.D{{Key: sortBy, Value: 1}, {Key: store.DomainFqdnKey, Value: 1}})
+	// IPs are stored as strings, so the subnet is matched and IPs are sorted after the query
+	// and the page is cut here
+	inMemory := filter.Subnet != nil || sortBy == store.DomainIpKey
+	if !inMemory {
+		opts.SetSkip(filter.Offset).SetLimit(filter.Limit)
+		total, err = r.collection.CountDocuments(r.store.ctx, query)
+		if err != nil {
+			log.Error().Err(err)
+			log.Debug().Msg("[database] error when trying to count records")
+			return nil, 0, err
+		}
+	}
+	result, err := r.collection.Find(r.store.ctx, query, opts)
+	if err != nil {
+		log.Error().Err(err)
+		log.Debug().Msg("[database] error when trying to list records")
+		return nil, 0, err
+	}
+	defer func(result *mongo.Cursor, ctx context.Context) {
+		err := result.Close(ctx)
+		if err != nil {
+			log.Error().Err(err)
+			log.Debug().Msg("[database] error when trying to close cursor")
+		}
+	}(result, r.store.ctx)
+	for result.Next(r.store.ctx) {
+		var d *entities.Domain
+		_ = result.Decode(&d)
+		if filter.Subnet != nil {
+			if ip := net.ParseIP(d.IP); ip == nil || !filter.Subnet.Contains(ip) {
+				continue
+			}
+		}
+		domains = append(domains, d)
+	}
+	if sortBy == store.DomainIpKey {
+		sort.SliceStable(domains, func(i, j int) bool {
+			return compareIps(domains[i].IP, domains[j].IP) < 0
+		})
+	}
+	if inMemory {
+		total = int64(len(domains))
+		domains = domains[min(filter.Offset, total):]
+		if filter.Limit > 0 && int64(len(domains)) > filter.Limit {
+			domains = domains[:filter.Limit]
+		}
+	}
+	return domains, total, nil
+}
+
+// compareIps compares IPs numerically, values which are not IPs go last.
+func compareIps(a, b string) int {
+	ipA, ipB := net.ParseIP(a).To16(), net.ParseIP(b).To16()
+	switch {
+	case ipA == nil && ipB == nil:
+		return 0
+	case ipA == nil:
+		return 1
+	case ipB == nil:
+		return -1
+	}
+	return bytes.Compare(ipA, ipB)
+}
+
 func (r *domainRepository) Update(domain *entities.Domain) error {
 	// TODO validation
-	filter := bson.D{{store.DomainUserIdKey, domain.UserId}}
-	update := bson.D{{"$set",
-		bson.D{
-			{store.DomainIpKey, domain.IP},
-			{store.DomainBasicAuthKey, domain.BasicAuth},
-			{store.DomainFullSslKey, domain.FullSsl},
-			{store.DomainDeleteAtKey, domain.DeleteAt},
-			{store.DomainPortKey, domain.Port},
-		},
-	}}
+	filter := bson.D{{Key: store.DomainFqdnKey, Value: domain.FQDN}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: store.DomainUserIdKey, Value: domain.UserId},
+		{Key: store.DomainUserNameKey, Value: domain.UserName},
+		{Key: store.DomainIpKey, Value: domain.IP},
+		{Key: store.DomainBasicAuthKey, Value: domain.BasicAuth},
+		{Key: store.DomainAuthUserKey, Value: domain.BasicAuthUser},
+		{Key: store.DomainAuthHashKey, Value: domain.BasicAuthHash},
+		{Key: store.DomainFullSslKey, Value: domain.FullSsl},
+		{Key: store.DomainDeleteAtKey, Value: domain.DeleteAt},
+		{Key: store.DomainPortKey, Value: domain.Port},
+		{Key: store.DomainRoutesKey, Value: domain.Routes},
+		{Key: store.DomainCoOwnersKey, Value: domain.CoOwners},
+		{Key: store.DomainAccessKey, Value: domain.Access},
+		{Key: store.DomainStateKey, Value: domain.State},
+		{Key: store.DomainPresetKey, Value: domain.Preset},
+		{Key: store.DomainProxyKey, Value: domain.Proxy},
+		{Key: store.DomainHeadersKey, Value: domain.Headers},
+		{Key: store.DomainLogsKey, Value: domain.Logs},
+		{Key: store.DomainWildcardKey, Value: domain.Wildcard},
+		{Key: store.DomainAliasesKey, Value: domain.Aliases},
+		{Key: store.DomainIpModeKey, Value: domain.IpMode},
+	}}}
 
 	result, err := r.collection.UpdateOne(r.store.ctx, filter, update)
 	if err != nil {
 		log.Error().Err(err).Msg("")
+		if mongo.IsDuplicateKeyError(err) {
+			return store.ErrDuplicate
+		}
 		return err
 	}
 	if result.MatchedCount == 0 {
@@ -63,15 +264,29 @@ func (r *domainRepository) Update(domain *entities.Domain) error {
 		log.Error().Msg("[database] no records found")
 		return store.ErrRecordNotFound
 	}
-	if result.ModifiedCount == 0 {
-		log.Debug().Msg(fmt.Sprintf("[database] tried to update record: %v", domain))
-		log.Error().Msg("[database] no records modified")
-		return store.ErrNoRowsUpdated
-	}
+	// an update with unchanged values matches the record without modifying it
 	log.Info().Msg(fmt.Sprintf("[database] updated record: %s", domain.FQDN))
 	log.Debug().Msg(fmt.Sprintf("[database] updated record: %v", domain))
 	return nil
 }
+
+func (r *domainRepository) UpdateHealth(fqdn string, health entities.Health) error {
+	filter := bson.D{{Key: store.DomainFqdnKey, Value: fqdn}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: store.DomainHealthKey, Value: health},
+	}}}
+	result, err := r.collection.UpdateOne(r.store.ctx, filter, update)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		log.Error().Msg(fmt.Sprintf("[database] no records found to update health: %s", fqdn))
+		return store.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *domainRepository) GetAllRecordsToDeleteInDays(days int) (domains []*entities.Domain, err error) {
 	result, err := r.collection.Find(r.store.ctx, bson.M{store.DomainDeleteAtKey: bson.M{
 		"$lte": primitive.NewDateTimeFromTime(time.Now().AddDate(0, 0, days)),
@@ -97,7 +312,7 @@ func (r *domainRepository) GetAllRecordsToDeleteInDays(days int) (domains []*ent
 }
 
 func (r *domainRepository) DeleteByFqdn(fqdn string) error {
-	filter := bson.D{{store.DomainFqdnKey, fqdn}}
+	filter := bson.D{{Key: store.DomainFqdnKey, Value: fqdn}}
 	opts := options.Delete().SetCollation(&options.Collation{})
 	result, err := r.collection.DeleteOne(r.store.ctx, filter, opts)
 	if err != nil {
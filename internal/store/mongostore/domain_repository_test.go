@@ -0,0 +1,27 @@
+package mongostore
+
+import "testing"
+
+func TestCompareIps(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{name: "equal", a: "10.0.0.5", b: "10.0.0.5", want: 0},
+		{name: "numeric, not lexical", a: "10.0.0.9", b: "10.0.0.10", want: -1},
+		{name: "higher octet first", a: "10.1.0.0", b: "10.0.255.255", want: 1},
+		{name: "ipv4 before ipv6", a: "192.168.0.1", b: "fd00::1", want: -1},
+		{name: "ipv4 and mapped ipv6 are equal", a: "10.0.0.5", b: "::ffff:10.0.0.5", want: 0},
+		{name: "ip before invalid value", a: "10.0.0.5", b: "localhost", want: -1},
+		{name: "invalid value after ip", a: "", b: "10.0.0.5", want: 1},
+		{name: "invalid values are equal", a: "localhost", b: "", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareIps(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareIps(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
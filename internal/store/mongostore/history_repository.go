@@ -0,0 +1,116 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type historyRepository struct {
+	store      *DataStore
+	collection *mongo.Collection
+}
+
+func (r *historyRepository) Append(revision *entities.Revision) error {
+	last, err := r.GetAllByDomainId(revision.Domain.Id, 1)
+	if err != nil {
+		return err
+	}
+	revision.Rev = 1
+	if len(last) > 0 {
+		revision.Rev = last[0].Rev + 1
+	}
+	_, err = r.collection.InsertOne(r.store.ctx, revision)
+	if err != nil {
+		log.Debug().Msg(fmt.Sprintf("[database] tried to append revision %d of %s", revision.Rev, revision.FQDN))
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	log.Info().Msg(fmt.Sprintf("[database] appended revision %d of %s", revision.Rev, revision.FQDN))
+	return nil
+}
+
+func (r *historyRepository) GetAllByDomainId(domainId string, limit int64) (revisions []*entities.Revision, err error) {
+	filter := bson.D{{Key: store.HistoryDomainIdKey, Value: domainId}}
+	opts := options.Find().SetSort(bson.D{{Key: store.HistoryRevKey, Value: -1}}).SetLimit(limit)
+	result, err := r.collection.Find(r.store.ctx, filter, opts)
+	if err != nil {
+		log.Error().Err(err)
+		log.Debug().Msg("[database] error when trying to find revisions")
+		return nil, err
+	}
+	defer func(result *mongo.Cursor, ctx context.Context) {
+		err := result.Close(ctx)
+		if err != nil {
+			log.Error().Err(err)
+			log.Debug().Msg("[database] error when trying to close cursor")
+		}
+	}(result, r.store.ctx)
+	for result.Next(r.store.ctx) {
+		var rev *entities.Revision
+		_ = result.Decode(&rev)
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+func (r *historyRepository) GetAllByIp(ip string, limit int64) (revisions []*entities.Revision, err error) {
+	filter := bson.M{"$or": []bson.M{
+		{store.HistoryIpKey: ip},
+		{store.HistoryRouteIpKey: ip},
+	}}
+	opts := options.Find().SetSort(bson.D{{Key: store.HistoryTimeKey, Value: -1}}).SetLimit(limit)
+	result, err := r.collection.Find(r.store.ctx, filter, opts)
+	if err != nil {
+		log.Error().Err(err)
+		log.Debug().Msg("[database] error when trying to find revisions by ip")
+		return nil, err
+	}
+	defer func(result *mongo.Cursor, ctx context.Context) {
+		err := result.Close(ctx)
+		if err != nil {
+			log.Error().Err(err)
+			log.Debug().Msg("[database] error when trying to close cursor")
+		}
+	}(result, r.store.ctx)
+	for result.Next(r.store.ctx) {
+		var rev *entities.Revision
+		_ = result.Decode(&rev)
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+func (r *historyRepository) GetLastByHostname(hostname string) (revision *entities.Revision, err error) {
+	filter := bson.M{"$or": []bson.M{
+		{store.HistoryFqdnKey: hostname},
+		{store.HistoryAliasesKey: hostname},
+	}}
+	opts := options.FindOne().SetSort(bson.D{{Key: store.HistoryTimeKey, Value: -1}})
+	err = r.collection.FindOne(r.store.ctx, filter, opts).Decode(&revision)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (r *historyRepository) GetByRev(domainId string, rev int) (revision *entities.Revision, err error) {
+	filter := bson.D{{Key: store.HistoryDomainIdKey, Value: domainId}, {Key: store.HistoryRevKey, Value: rev}}
+	err = r.collection.FindOne(r.store.ctx, filter).Decode(&revision)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return revision, nil
+}
@@ -0,0 +1,86 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reservationRepository struct {
+	store      *DataStore
+	collection *mongo.Collection
+}
+
+func (r *reservationRepository) Save(reservation *entities.Reservation) error {
+	filter := bson.D{{Key: store.ReservationFqdnKey, Value: reservation.FQDN}}
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.collection.ReplaceOne(r.store.ctx, filter, reservation, opts)
+	if err != nil {
+		log.Debug().Msg(fmt.Sprintf("[database] tried to save reservation: %v", reservation))
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	log.Info().Msg(fmt.Sprintf("[database] saved reservation: %s", reservation.FQDN))
+	return nil
+}
+
+func (r *reservationRepository) GetByFqdn(fqdn string) (reservation *entities.Reservation, err error) {
+	filter := bson.M{
+		store.ReservationFqdnKey:      fqdn,
+		store.ReservationExpiresAtKey: bson.M{"$gt": primitive.NewDateTimeFromTime(time.Now())},
+	}
+	err = r.collection.FindOne(r.store.ctx, filter).Decode(&reservation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepository) GetAll() (reservations []*entities.Reservation, err error) {
+	filter := bson.M{store.ReservationExpiresAtKey: bson.M{"$gt": primitive.NewDateTimeFromTime(time.Now())}}
+	opts := options.Find().SetSort(bson.D{{Key: store.ReservationExpiresAtKey, Value: 1}})
+	result, err := r.collection.Find(r.store.ctx, filter, opts)
+	if err != nil {
+		log.Error().Err(err)
+		log.Debug().Msg("[database] error when trying to find reservations")
+		return nil, err
+	}
+	defer func(result *mongo.Cursor, ctx context.Context) {
+		err := result.Close(ctx)
+		if err != nil {
+			log.Error().Err(err)
+			log.Debug().Msg("[database] error when trying to close cursor")
+		}
+	}(result, r.store.ctx)
+	for result.Next(r.store.ctx) {
+		var res *entities.Reservation
+		_ = result.Decode(&res)
+		reservations = append(reservations, res)
+	}
+	return reservations, nil
+}
+
+func (r *reservationRepository) DeleteByFqdn(fqdn string) error {
+	filter := bson.D{{Key: store.ReservationFqdnKey, Value: fqdn}}
+	result, err := r.collection.DeleteOne(r.store.ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return store.ErrNoRowsDeleted
+	}
+	log.Info().Msg(fmt.Sprintf("[database] deleted reservation: %s", fqdn))
+	return nil
+}
@@ -10,12 +10,17 @@ import (
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 )
 
+const legacyDomainUserIdIndex = "user_id_1"
+
 type DataStore struct {
-	client           *mongo.Client
-	db               *mongo.Database
-	ctx              context.Context
-	domainRepository *domainRepository
-	vpnEuRepository  *vpnEuRepository
+	client                 *mongo.Client
+	db                     *mongo.Database
+	ctx                    context.Context
+	domainRepository       *domainRepository
+	vpnEuRepository        *vpnEuRepository
+	reservationRepository  *reservationRepository
+	historyRepository      *historyRepository
+	verificationRepository *verificationRepository
 }
 
 func New(uri string) *DataStore {
@@ -48,11 +53,33 @@ func (s *DataStore) DomainRepository() store.DomainRepository {
 	}
 
 	c := s.db.Collection(store.DomainCollection)
-	_, err := c.Indexes().CreateOne(
+	dropLegacyDomainIndex(c)
+	_, err := c.Indexes().CreateMany(
 		context.Background(),
-		mongo.IndexModel{
-			Keys:    bson.D{{Key: store.DomainUserIdKey, Value: 1}},
-			Options: options.Index().SetUnique(true),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: store.DomainUserIdKey, Value: 1}},
+				Options: options.Index(),
+			},
+			{
+				Keys:    bson.D{{Key: store.DomainFqdnKey, Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: store.DomainCoOwnersKey, Value: 1}},
+				Options: options.Index(),
+			},
+			{
+				// domains without aliases have no or null aliases and are not indexed
+				Keys: bson.D{{Key: store.DomainAliasesKey, Value: 1}},
+				Options: options.Index().SetUnique(true).SetPartialFilterExpression(
+					bson.M{store.DomainAliasesKey: bson.M{"$type": "string"}},
+				),
+			},
+			{
+				Keys:    bson.D{{Key: store.DomainIpKey, Value: 1}},
+				Options: options.Index(),
+			},
 		},
 	)
 	if err != nil {
@@ -65,6 +92,32 @@ func (s *DataStore) DomainRepository() store.DomainRepository {
 	return s.domainRepository
 }
 
+// dropLegacyDomainIndex removes the unique user_id index left from the time
+// when every user could own only one domain.
+func dropLegacyDomainIndex(c *mongo.Collection) {
+	cursor, err := c.Indexes().List(context.Background())
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return
+	}
+	var indexes []bson.M
+	if err = cursor.All(context.Background(), &indexes); err != nil {
+		log.Error().Err(err).Msg("")
+		return
+	}
+	for _, index := range indexes {
+		unique, _ := index["unique"].(bool)
+		if !unique || index["name"] != legacyDomainUserIdIndex {
+			continue
+		}
+		if _, err = c.Indexes().DropOne(context.Background(), legacyDomainUserIdIndex); err != nil {
+			log.Error().Err(err).Msg("")
+			return
+		}
+		log.Info().Msgf("[database] dropped legacy index %s", legacyDomainUserIdIndex)
+	}
+}
+
 func (s *DataStore) VPNEURepository() store.VPNEURepository {
 	if s.vpnEuRepository != nil {
 		return s.vpnEuRepository
@@ -87,6 +140,102 @@ func (s *DataStore) VPNEURepository() store.VPNEURepository {
 	return s.vpnEuRepository
 }
 
+func (s *DataStore) ReservationRepository() store.ReservationRepository {
+	if s.reservationRepository != nil {
+		return s.reservationRepository
+	}
+	c := s.db.Collection(store.ReservationCollection)
+	_, err := c.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: store.ReservationFqdnKey, Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: store.ReservationExpiresAtKey, Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+	}
+	s.reservationRepository = &reservationRepository{
+		store:      s,
+		collection: c,
+	}
+	return s.reservationRepository
+}
+
+func (s *DataStore) HistoryRepository() store.HistoryRepository {
+	if s.historyRepository != nil {
+		return s.historyRepository
+	}
+	c := s.db.Collection(store.HistoryCollection)
+	_, err := c.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: store.HistoryDomainIdKey, Value: 1}, {Key: store.HistoryRevKey, Value: -1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: store.HistoryFqdnKey, Value: 1}},
+				Options: options.Index(),
+			},
+			{
+				Keys:    bson.D{{Key: store.HistoryAliasesKey, Value: 1}},
+				Options: options.Index(),
+			},
+			{
+				Keys:    bson.D{{Key: store.HistoryIpKey, Value: 1}},
+				Options: options.Index(),
+			},
+			{
+				Keys:    bson.D{{Key: store.HistoryRouteIpKey, Value: 1}},
+				Options: options.Index(),
+			},
+		},
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+	}
+	s.historyRepository = &historyRepository{
+		store:      s,
+		collection: c,
+	}
+	return s.historyRepository
+}
+
+func (s *DataStore) VerificationRepository() store.VerificationRepository {
+	if s.verificationRepository != nil {
+		return s.verificationRepository
+	}
+	c := s.db.Collection(store.VerificationCollection)
+	_, err := c.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: store.VerificationUserIdKey, Value: 1}, {Key: store.VerificationIpKey, Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: store.VerificationExpiresAtKey, Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+	}
+	s.verificationRepository = &verificationRepository{
+		store:      s,
+		collection: c,
+	}
+	return s.verificationRepository
+}
+
 func (s *DataStore) Close() error {
 	return s.client.Disconnect(s.ctx)
 }
@@ -0,0 +1,51 @@
+package mongostore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/store"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type verificationRepository struct {
+	store      *DataStore
+	collection *mongo.Collection
+}
+
+func (r *verificationRepository) Save(verification *entities.IpVerification) error {
+	filter := bson.D{
+		{Key: store.VerificationUserIdKey, Value: verification.UserId},
+		{Key: store.VerificationIpKey, Value: verification.IP},
+	}
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.collection.ReplaceOne(r.store.ctx, filter, verification, opts)
+	if err != nil {
+		log.Debug().Msg(fmt.Sprintf("[database] tried to save ip verification of %s for %s", verification.IP, verification.UserId))
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	log.Info().Msg(fmt.Sprintf("[database] saved ip verification of %s for %s", verification.IP, verification.UserId))
+	return nil
+}
+
+func (r *verificationRepository) Get(userId, ip string) (verification *entities.IpVerification, err error) {
+	filter := bson.M{
+		store.VerificationUserIdKey:    userId,
+		store.VerificationIpKey:        ip,
+		store.VerificationExpiresAtKey: bson.M{"$gt": primitive.NewDateTimeFromTime(time.Now())},
+	}
+	err = r.collection.FindOne(r.store.ctx, filter).Decode(&verification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return verification, nil
+}
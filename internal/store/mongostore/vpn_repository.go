@@ -65,12 +65,10 @@ func (r *vpnEuRepository) GetAllRecordsToDeactivateInMinutes(minutes int) (recor
 }
 
 func (r *vpnEuRepository) SetInactive(record *entities.VPNEU) error {
-	filter := bson.D{{store.VpnEuUserEmail, record.UserEmail}, {store.VpnEUActive, true}}
-	update := bson.D{{"$set",
-		bson.D{
-			{store.VpnEUActive, false},
-		},
-	}}
+	filter := bson.D{{Key: store.VpnEuUserEmail, Value: record.UserEmail}, {Key: store.VpnEUActive, Value: true}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: store.VpnEUActive, Value: false},
+	}}}
 
 	result, err := r.collection.UpdateOne(r.store.ctx, filter, update)
 	if err != nil {
@@ -4,12 +4,60 @@ import "github.com/1k-off/dev-helper-bot/internal/entities"
 
 type DomainRepository interface {
 	Create(d *entities.Domain) error
-	Get(userId string) (domain *entities.Domain, err error)
+	GetByFqdn(fqdn string) (domain *entities.Domain, err error)
+	// GetByHostname returns the domain with the FQDN or the alias
+	GetByHostname(hostname string) (domain *entities.Domain, err error)
+	// GetAllByUserId returns domains owned or co-owned by the user
+	GetAllByUserId(userId string) (domains []*entities.Domain, err error)
+	// GetAllByIp returns domains with the IP as the upstream or a route target sorted by fqdn
+	GetAllByIp(ip string) (domains []*entities.Domain, err error)
+	// GetAll returns all domains sorted by fqdn
+	GetAll() (domains []*entities.Domain, err error)
+	// List returns a page of domains matching the filter and the total number of matching domains.
+	// IPs are sorted numerically. The subnet filter and sorting by IP are applied in memory to all
+	// domains matching the other filters.
+	List(filter DomainFilter) (domains []*entities.Domain, total int64, err error)
 	Update(domain *entities.Domain) error
+	// UpdateHealth stores the last health check result of the domain
+	UpdateHealth(fqdn string, health entities.Health) error
 	GetAllRecordsToDeleteInDays(days int) (domains []*entities.Domain, err error)
 	DeleteByFqdn(fqdn string) error
 }
 
+// ReservationRepository stores names of deleted domains. Expired reservations are
+// never returned and are removed by the database.
+type ReservationRepository interface {
+	// Save creates the reservation or replaces the existing one with the same fqdn
+	Save(reservation *entities.Reservation) error
+	GetByFqdn(fqdn string) (reservation *entities.Reservation, err error)
+	GetAll() (reservations []*entities.Reservation, err error)
+	DeleteByFqdn(fqdn string) error
+}
+
+// VerificationRepository stores IP verifications, one per user and IP. Expired verifications
+// are never returned and are removed by the database.
+type VerificationRepository interface {
+	// Save creates the verification or replaces the existing one of the user and IP
+	Save(verification *entities.IpVerification) error
+	Get(userId, ip string) (verification *entities.IpVerification, err error)
+}
+
+// HistoryRepository stores revisions of domains. Revisions are kept after the domain is deleted
+// and belong to the domain id, so a new domain with the same FQDN starts a new history.
+type HistoryRepository interface {
+	// Append stores the revision with the next revision number of its domain
+	Append(revision *entities.Revision) error
+	// GetAllByDomainId returns the last revisions of the domain, newest first
+	GetAllByDomainId(domainId string, limit int64) (revisions []*entities.Revision, err error)
+	// GetAllByIp returns the last revisions of all domains, deleted ones included, which had
+	// the IP as the upstream or a route target, newest first
+	GetAllByIp(ip string, limit int64) (revisions []*entities.Revision, err error)
+	// GetLastByHostname returns the newest revision of any domain, deleted ones included, which
+	// had the hostname as its fqdn or alias
+	GetLastByHostname(hostname string) (revision *entities.Revision, err error)
+	GetByRev(domainId string, rev int) (revision *entities.Revision, err error)
+}
+
 type VPNEURepository interface {
 	Create(vpnRecord *entities.VPNEU) error
 	GetAllRecordsToDeactivateInMinutes(minutes int) (records []*entities.VPNEU, err error)
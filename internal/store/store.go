@@ -3,5 +3,8 @@ package store
 type Store interface {
 	DomainRepository() DomainRepository
 	VPNEURepository() VPNEURepository
+	ReservationRepository() ReservationRepository
+	HistoryRepository() HistoryRepository
+	VerificationRepository() VerificationRepository
 	Close() error
 }
@@ -0,0 +1,95 @@
+package webserver
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"golang.org/x/crypto/bcrypt"
+	"math/big"
+	"os"
+	"path/filepath"
+)
+
+const (
+	passwdPath        = configBasePath + "passwd/"
+	basicAuthUserLen  = 8
+	basicAuthPassLen  = 20
+	basicAuthAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// ensureBasicAuth generates credentials for the domain when basic auth is enabled and
+// the domain has none yet. The plain password is set only in BasicAuthPassword and is
+// never stored, so it must be delivered to the owner right after the config is created.
+func (s *Server) ensureBasicAuth(c *entities.Domain) error {
+	if !c.BasicAuth || c.BasicAuthHash != "" {
+		return nil
+	}
+	user, err := randomString(basicAuthUserLen, basicAuthAlphabet[:26])
+	if err != nil {
+		return err
+	}
+	password, err := randomString(basicAuthPassLen, basicAuthAlphabet)
+	if err != nil {
+		return err
+	}
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+	c.BasicAuthUser = user
+	c.BasicAuthHash = hash
+	c.BasicAuthPassword = password
+	return nil
+}
+
+// hashPassword returns the password hash in the format supported by the server:
+// salted SHA-1 for nginx and bcrypt for caddy.
+func (s *Server) hashPassword(password string) (string, error) {
+	if s.kind == ServerCaddy {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		return string(hash), err
+	}
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(append([]byte(password), salt...))
+	return "{SSHA}" + base64.StdEncoding.EncodeToString(append(sum[:], salt...)), nil
+}
+
+// writePasswdFile writes the htpasswd file for nginx and returns its absolute path.
+func writePasswdFile(c *entities.Domain) (string, error) {
+	if err := os.MkdirAll(passwdPath, 0755); err != nil {
+		return "", err
+	}
+	path, err := filepath.Abs(passwdPath + c.FQDN)
+	if err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(path, []byte(c.BasicAuthUser+":"+c.BasicAuthHash+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func removePasswdFile(domain string) error {
+	err := os.Remove(passwdPath + domain)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func randomString(length int, alphabet string) (string, error) {
+	b := make([]byte, length)
+	max := big.NewInt(int64(len(alphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphabet[n.Int64()]
+	}
+	return string(b), nil
+}
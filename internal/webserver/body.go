@@ -0,0 +1,26 @@
+package webserver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// bodySizeRegexp matches request body sizes in the nginx format, e.g. 500k, 10m or 1g.
+var bodySizeRegexp = regexp.MustCompile(`^[1-9][0-9]*[kmg]$`)
+
+// ValidateBodySize checks a max request body size set by a user.
+func ValidateBodySize(value string) error {
+	if !bodySizeRegexp.MatchString(value) {
+		return fmt.Errorf("invalid size %q, expected a number with k, m or g suffix like 10m", value)
+	}
+	return nil
+}
+
+// caddyBodySize converts a body size from the nginx format to the caddy one, e.g. 10m to 10MB.
+func caddyBodySize(value string) string {
+	if value == "" {
+		return ""
+	}
+	return strings.ToUpper(value) + "B"
+}
@@ -0,0 +1,36 @@
+package webserver
+
+import "testing"
+
+func TestValidateBodySize(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "500k"},
+		{value: "10m"},
+		{value: "1g"},
+		{value: "", wantErr: true},
+		{value: "10", wantErr: true},
+		{value: "0m", wantErr: true},
+		{value: "010m", wantErr: true},
+		{value: "10M", wantErr: true},
+		{value: "10mb", wantErr: true},
+		{value: "1.5g", wantErr: true},
+		{value: "-1m", wantErr: true},
+		{value: "10m;", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := ValidateBodySize(tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateBodySize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCaddyBodySize(t *testing.T) {
+	for value, want := range map[string]string{"": "", "500k": "500KB", "10m": "10MB", "1g": "1GB"} {
+		if got := caddyBodySize(value); got != want {
+			t.Errorf("caddyBodySize(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
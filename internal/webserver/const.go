@@ -5,6 +5,12 @@ const (
 	SchemeHttps = "https"
 )
 
+// Upstream protocols besides http and https, which are selected by the FullSsl flag.
+const (
+	ProtocolH2c  = "h2c"
+	ProtocolGrpc = "grpc"
+)
+
 const (
 	ServerCaddy = "caddy"
 	ServerNginx = "nginx"
@@ -0,0 +1,179 @@
+package webserver
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"github.com/1k-off/dev-helper-bot/internal/webserver/nginx"
+	"github.com/rs/zerolog/log"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	logsPath = configBasePath + "logs/"
+	// logTailBytes limits how much of a log file is read to get its tail
+	logTailBytes = 1 << 20
+)
+
+// logFiles returns absolute paths of the access and error logs of the domain.
+// Caddy writes errors to the access log, so both paths are the same for it.
+func (s *Server) logFiles(domain string) (access, errors string, err error) {
+	access, err = filepath.Abs(logsPath + domain + ".access.log")
+	if err != nil {
+		return "", "", err
+	}
+	if s.kind == ServerCaddy {
+		return access, access, nil
+	}
+	errors, err = filepath.Abs(logsPath + domain + ".error.log")
+	return access, errors, err
+}
+
+// logData returns template data for the domain logs, empty when the logs are disabled.
+func (s *Server) logData(c *entities.Domain) (map[string]interface{}, error) {
+	if !c.Logs {
+		return map[string]interface{}{}, nil
+	}
+	if err := os.MkdirAll(logsPath, 0755); err != nil {
+		return nil, err
+	}
+	access, errors, err := s.logFiles(c.FQDN)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"accesslog": access,
+		"errorlog":  errors,
+		"logsize":   s.settings.LogMaxSizeMB,
+		"logkeep":   s.settings.LogKeep,
+	}, nil
+}
+
+// Logs returns the last lines of the access or error log of the domain.
+// Caddy has no separate error log, so responses with 5xx status are returned instead.
+func (s *Server) Logs(domain string, lines int, errors bool) (string, error) {
+	access, errorLog, err := s.logFiles(domain)
+	if err != nil {
+		return "", err
+	}
+	path := access
+	if errors {
+		path = errorLog
+	}
+	tail, err := readTail(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var result []string
+	for _, line := range strings.Split(strings.TrimRight(string(tail), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if errors && s.kind == ServerCaddy && !strings.Contains(line, `"status":5`) {
+			continue
+		}
+		result = append(result, line)
+	}
+	if len(result) > lines {
+		result = result[len(result)-lines:]
+	}
+	return strings.Join(result, "\n"), nil
+}
+
+// readTail returns the end of the file without the first partial line.
+func readTail(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - logTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i != -1 {
+			data = data[i+1:]
+		}
+	}
+	return data, nil
+}
+
+// RotateLogs rotates nginx logs bigger than the size cap. Caddy rotates its logs itself.
+func (s *Server) RotateLogs() error {
+	if s.kind != ServerNginx {
+		return nil
+	}
+	files, err := filepath.Glob(logsPath + "*.log")
+	if err != nil {
+		return err
+	}
+	maxSize := int64(s.settings.LogMaxSizeMB) << 20
+	rotated := false
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || info.Size() < maxSize {
+			continue
+		}
+		if err = rotateFile(file, s.settings.LogKeep); err != nil {
+			return err
+		}
+		rotated = true
+		log.Info().Msg(fmt.Sprintf("[%s] rotated log %s", s.kind, file))
+	}
+	if rotated && !Debug {
+		// nginx keeps writing to the renamed files until it reopens them
+		return nginx.Reopen()
+	}
+	return nil
+}
+
+// rotateFile renames file to file.1, file.1 to file.2 and so on, keeping the given number of files.
+func rotateFile(file string, keep int) error {
+	if err := os.Remove(fmt.Sprintf("%s.%d", file, keep)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := keep - 1; i >= 1; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", file, i), fmt.Sprintf("%s.%d", file, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if keep == 0 {
+		return os.Remove(file)
+	}
+	return os.Rename(file, file+".1")
+}
+
+// DeleteLogs removes all the log files of the domain. Rotated files are named <name>.log.N
+// by RotateLogs for nginx and <name>-<timestamp>.log by caddy.
+func (s *Server) DeleteLogs(domain string) error {
+	for _, kind := range []string{"access", "error"} {
+		files, err := filepath.Glob(fmt.Sprintf("%s%s.%s*.log*", logsPath, domain, kind))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err = os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -18,3 +18,10 @@ func Reload() error {
 	}
 	return nil
 }
+
+// Reopen makes nginx reopen log files after they are rotated.
+func Reopen() error {
+	cmd := exec.Command("nginx", "-s", "reopen")
+	_, err := cmd.Output()
+	return err
+}
@@ -0,0 +1,94 @@
+package webserver
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Default proxy timeouts in seconds.
+const (
+	defaultConnectTimeout = 120
+	defaultSendTimeout    = 120
+	defaultReadTimeout    = 180
+)
+
+// Preset is an admin-defined set of domain settings for a project stack.
+// Port, scheme and auth are copied to the domain when the preset is applied,
+// timeouts and vars are expanded every time the config is rendered. Vars are passed to
+// the templates as .vars for customized templates, the bundled ones don't use them.
+type Preset struct {
+	Port      string            `mapstructure:"port"`
+	Scheme    string            `mapstructure:"scheme"`
+	BasicAuth *bool             `mapstructure:"basic_auth"`
+	Timeouts  Timeouts          `mapstructure:"timeouts"`
+	Vars      map[string]string `mapstructure:"vars"`
+}
+
+// Timeouts of proxying to the upstream as durations like 30s or 5m.
+// Empty values mean the default timeouts.
+type Timeouts struct {
+	Connect string `mapstructure:"connect"`
+	Send    string `mapstructure:"send"`
+	Read    string `mapstructure:"read"`
+}
+
+// proxyTimeouts are timeouts in seconds passed to the templates.
+// Custom is set when any of the timeouts differs from the defaults.
+type proxyTimeouts struct {
+	Connect int
+	Send    int
+	Read    int
+	Custom  bool
+}
+
+// Validate checks the preset values.
+func (p Preset) Validate() error {
+	if p.Port != "" {
+		if port, err := strconv.Atoi(p.Port); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q", p.Port)
+		}
+	}
+	if p.Scheme != "" && p.Scheme != SchemeHttp && p.Scheme != SchemeHttps {
+		return fmt.Errorf("invalid scheme %q, expected %s or %s", p.Scheme, SchemeHttp, SchemeHttps)
+	}
+	for _, t := range []string{p.Timeouts.Connect, p.Timeouts.Send, p.Timeouts.Read} {
+		if _, err := parseTimeout(t); t != "" && err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seconds returns the timeouts with defaults applied. Values are validated on config load.
+func (t Timeouts) seconds() proxyTimeouts {
+	pt := proxyTimeouts{
+		Connect: defaultConnectTimeout,
+		Send:    defaultSendTimeout,
+		Read:    defaultReadTimeout,
+	}
+	for _, v := range []struct {
+		value  string
+		target *int
+	}{{t.Connect, &pt.Connect}, {t.Send, &pt.Send}, {t.Read, &pt.Read}} {
+		if s, err := parseTimeout(v.value); err == nil {
+			*v.target = s
+			pt.Custom = true
+		}
+	}
+	return pt
+}
+
+// ValidateTimeout checks a timeout set by a user.
+func ValidateTimeout(value string) error {
+	_, err := parseTimeout(value)
+	return err
+}
+
+func parseTimeout(value string) (int, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < time.Second {
+		return 0, fmt.Errorf("invalid timeout %q, expected a duration like 30s or 5m", value)
+	}
+	return int(d.Seconds()), nil
+}
@@ -6,10 +6,12 @@ import (
 	caddy_svc "github.com/1k-off/dev-helper-bot/internal/webserver/caddy-svc"
 	"github.com/1k-off/dev-helper-bot/internal/webserver/nginx"
 	"github.com/rs/zerolog/log"
-	"html/template"
+	"html"
 	"net"
 	"os"
+	"sort"
 	"strconv"
+	"text/template"
 )
 
 type IP struct {
@@ -26,11 +28,31 @@ var (
 type Webserver interface {
 	Create(c *entities.Domain) error
 	Delete(domain string) error
+	// Config returns the rendered config of the domain
+	Config(domain string) (string, error)
+	Logs(domain string, lines int, errors bool) (string, error)
+	RotateLogs() error
+	DeleteLogs(domain string) error
 }
 
 type Server struct {
 	kind         string
 	templatePath string
+	settings     Settings
+}
+
+// Settings holds config values used to render domain configs.
+type Settings struct {
+	// OfficeNetworks can skip basic auth when the domain allows it
+	OfficeNetworks []string
+	// VpnNetworks are the only allowed viewers of VPN-only domains
+	VpnNetworks []string
+	// Presets are expanded when configs of domains with a preset are rendered
+	Presets map[string]Preset
+	// LogMaxSizeMB is the size of domain logs that triggers rotation
+	LogMaxSizeMB int
+	// LogKeep is the number of rotated domain logs to keep
+	LogKeep int
 }
 
 func init() {
@@ -59,7 +81,7 @@ func init() {
 	}
 }
 
-func New(s string) *Server {
+func New(s string, settings Settings) *Server {
 	if _, err := os.Stat(configBasePath + s); os.IsNotExist(err) {
 		err := os.Mkdir(configBasePath+s, os.ModeDir)
 		if err != nil {
@@ -70,6 +92,7 @@ func New(s string) *Server {
 	return &Server{
 		kind:         s,
 		templatePath: "./config/" + s + ".conf.tpl",
+		settings:     settings,
 	}
 }
 
@@ -78,6 +101,9 @@ func (s *Server) Create(c *entities.Domain) error {
 	if !c.BasicAuth {
 		ba = "off"
 	}
+	if err := s.ensureBasicAuth(c); err != nil {
+		return err
+	}
 
 	scheme := SchemeHttp
 	if c.FullSsl {
@@ -91,14 +117,64 @@ func (s *Server) Create(c *entities.Domain) error {
 		}
 	}
 
-	configData := map[string]string{
-		"ip":        c.IP,
+	ip, port, routes := splitRoutes(c)
+	preset := s.settings.Presets[c.Preset]
+	timeouts := preset.Timeouts
+	if c.Proxy.Timeout != "" {
+		timeouts.Send, timeouts.Read = c.Proxy.Timeout, c.Proxy.Timeout
+	}
+	// nginx proxies gRPC with grpc_* directives
+	proxyDirective, proxyScheme := "proxy", scheme
+	if c.Proxy.Protocol == ProtocolGrpc {
+		proxyDirective, proxyScheme = "grpc", "grpc"
+		if c.FullSsl {
+			proxyScheme = "grpcs"
+		}
+	}
+
+	configData := map[string]interface{}{
+		"ip":        ip,
 		"domain":    c.FQDN,
 		"basicauth": ba,
 		"scheme":    scheme,
-		"port":      c.Port,
+		"port":      port,
+		"routes":    routes,
+		"authuser":  c.BasicAuthUser,
+		"authhash":  c.BasicAuthHash,
+		"allow":     s.allowedNetworks(c),
+		"bypass":    s.bypassNetworks(c),
+		"paused":    c.IsPaused(),
+		"owner":     html.EscapeString(c.UserName),
+		"preset":    c.Preset,
+		"timeouts":  timeouts.seconds(),
+		"vars":      preset.Vars,
+		"websocket": c.Proxy.Websocket,
+		"protocol":  c.Proxy.Protocol,
+		"maxbody":   c.Proxy.MaxBody,
+		"caddybody": caddyBodySize(c.Proxy.MaxBody),
+		"directive": proxyDirective,
+		"passproto": proxyScheme,
+		"host":      hostHeader(c),
+		"reqheader": headers(c, entities.HeaderRequest),
+		"resheader": headers(c, entities.HeaderResponse),
+		"wildcard":  c.Wildcard,
+		"aliases":   c.Aliases,
+	}
+	logData, err := s.logData(c)
+	if err != nil {
+		return err
+	}
+	for k, v := range logData {
+		configData[k] = v
 	}
 	if _, err := os.Stat(configBasePath + s.kind + "/" + c.FQDN); os.IsNotExist(err) {
+		if s.kind == ServerNginx && c.BasicAuth {
+			passwdFile, err := writePasswdFile(c)
+			if err != nil {
+				return err
+			}
+			configData["passwdfile"] = passwdFile
+		}
 		t, err := template.ParseFiles(s.templatePath)
 		if err != nil {
 			return err
@@ -127,10 +203,68 @@ func (s *Server) Create(c *entities.Domain) error {
 	}
 }
 
+// splitRoutes returns the upstream for the root location and the rest of the routes
+// ordered from the longest path, so more specific routes win in both servers.
+func splitRoutes(c *entities.Domain) (ip, port string, routes []entities.Route) {
+	ip, port = c.IP, c.Port
+	for _, r := range c.Routes {
+		if r.Path == "/" {
+			ip, port = r.IP, r.Port
+			continue
+		}
+		routes = append(routes, r)
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].Path) > len(routes[j].Path)
+	})
+	return ip, port, routes
+}
+
+// hostHeader returns the Host header override, empty means the original host is passed.
+func hostHeader(c *entities.Domain) string {
+	for _, h := range c.Headers {
+		if h.Kind == entities.HeaderRequest && h.Name == "Host" {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// headers returns custom headers of the kind except the Host header.
+func headers(c *entities.Domain, kind string) []entities.Header {
+	var result []entities.Header
+	for _, h := range c.Headers {
+		if h.Kind == kind && h.Name != "Host" {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// allowedNetworks returns networks allowed to open the domain, empty means everyone.
+func (s *Server) allowedNetworks(c *entities.Domain) []string {
+	allowed := append([]string{}, c.Access.AllowedCidrs...)
+	if c.Access.VpnOnly {
+		allowed = append(allowed, s.settings.VpnNetworks...)
+	}
+	return allowed
+}
+
+// bypassNetworks returns networks that can open the domain without basic auth.
+func (s *Server) bypassNetworks(c *entities.Domain) []string {
+	if !c.BasicAuth || !c.Access.OfficeBypass {
+		return nil
+	}
+	return s.settings.OfficeNetworks
+}
+
 func (s *Server) Delete(domain string) error {
 	if err := os.Remove(configBasePath + s.kind + "/" + domain); err != nil {
 		return err
 	}
+	if err := removePasswdFile(domain); err != nil {
+		return err
+	}
 	if !Debug {
 		if err := s.reload(); err != nil {
 			return err
@@ -140,6 +274,14 @@ func (s *Server) Delete(domain string) error {
 	return nil
 }
 
+func (s *Server) Config(domain string) (string, error) {
+	b, err := os.ReadFile(configBasePath + s.kind + "/" + domain)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
 func (s *Server) reload() error {
 	switch s.kind {
 	case ServerCaddy:
@@ -0,0 +1,293 @@
+package webserver
+
+import (
+	"github.com/1k-off/dev-helper-bot/internal/entities"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// renderConfig creates the config of the domain with the bundled template of the server
+// in a temporary directory and returns it.
+func renderConfig(t *testing.T, kind string, settings Settings, d *entities.Domain) string {
+	t.Helper()
+	templatePath, err := filepath.Abs("../../config/" + kind + ".conf.tpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir(kind, 0755); err != nil {
+		t.Fatal(err)
+	}
+	debug := Debug
+	Debug = true
+	t.Cleanup(func() { Debug = debug })
+
+	s := New(kind, settings)
+	s.templatePath = templatePath
+	if err := s.Create(d); err != nil {
+		t.Fatalf("Create(%s) error = %v", d.FQDN, err)
+	}
+	config, err := s.Config(d.FQDN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// templates can have CRLF line endings, which are kept in the rendered config
+	return strings.ReplaceAll(config, "\r\n", "\n")
+}
+
+type renderTest struct {
+	name    string
+	kind    string
+	domain  entities.Domain
+	want    []string
+	notWant []string
+}
+
+func runRenderTests(t *testing.T, settings Settings, tests []renderTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.domain
+			if d.FQDN == "" {
+				d.FQDN = "j-doe.domain.tld"
+			}
+			config := renderConfig(t, tt.kind, settings, &d)
+			for _, s := range tt.want {
+				if !strings.Contains(config, s) {
+					t.Errorf("config has no %q:\n%s", s, config)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(config, s) {
+					t.Errorf("config has %q:\n%s", s, config)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateRoutes(t *testing.T) {
+	routes := []entities.Route{
+		{Path: "/api", IP: "10.0.0.6", Port: "8080"},
+		{Path: "/api/v2", IP: "10.0.0.7", Port: "9090"},
+	}
+	root := []entities.Route{{Path: "/", IP: "10.0.0.8", Port: "4000"}}
+	runRenderTests(t, Settings{}, []renderTest{
+		{
+			name:    "nginx without routes",
+			kind:    ServerNginx,
+			domain:  entities.Domain{IP: "10.0.0.5", Port: "3000"},
+			want:    []string{"server 10.0.0.5:3000;", "location / {"},
+			notWant: []string{"location ="},
+		},
+		{
+			name:   "nginx routes",
+			kind:   ServerNginx,
+			domain: entities.Domain{IP: "10.0.0.5", Port: "3000", Routes: routes},
+			want: []string{
+				"server 10.0.0.5:3000;",
+				"location = /api {\n        proxy_pass http://10.0.0.6:8080;",
+				"location /api/ {\n        proxy_pass http://10.0.0.6:8080;",
+				"location = /api/v2 {\n        proxy_pass http://10.0.0.7:9090;",
+				"location /api/v2/ {\n        proxy_pass http://10.0.0.7:9090;",
+				"location / {\n        proxy_pass http://j-doe.domain.tld-upstream;",
+			},
+		},
+		{
+			name:    "nginx root route overrides the upstream",
+			kind:    ServerNginx,
+			domain:  entities.Domain{IP: "10.0.0.5", Port: "3000", Routes: root},
+			want:    []string{"server 10.0.0.8:4000;"},
+			notWant: []string{"10.0.0.5", "location = /"},
+		},
+		{
+			name:   "caddy routes from the longest path",
+			kind:   ServerCaddy,
+			domain: entities.Domain{IP: "10.0.0.5", Port: "3000", Routes: routes},
+			want: []string{
+				"@route0 path /api/v2 /api/v2/*\n        handle @route0 {\n                reverse_proxy {\n                        to http://10.0.0.7:9090",
+				"@route1 path /api /api/*\n        handle @route1 {\n                reverse_proxy {\n                        to http://10.0.0.6:8080",
+				"handle {\n                reverse_proxy {\n                        to http://10.0.0.5:3000",
+			},
+		},
+		{
+			name:    "caddy root route overrides the upstream",
+			kind:    ServerCaddy,
+			domain:  entities.Domain{IP: "10.0.0.5", Port: "3000", Routes: root},
+			want:    []string{"to http://10.0.0.8:4000"},
+			notWant: []string{"10.0.0.5", "@route"},
+		},
+	})
+}
+
+func TestCreateAccess(t *testing.T) {
+	settings := Settings{OfficeNetworks: []string{"1.2.3.0/24"}, VpnNetworks: []string{"10.8.0.0/16"}}
+	allowlist := entities.Access{AllowedCidrs: []string{"5.6.7.8/32"}}
+	vpnOnly := entities.Access{AllowedCidrs: []string{"5.6.7.8/32"}, VpnOnly: true}
+	bypass := entities.Access{OfficeBypass: true}
+	runRenderTests(t, settings, []renderTest{
+		{
+			name:    "nginx open",
+			kind:    ServerNginx,
+			domain:  entities.Domain{IP: "10.0.0.5"},
+			notWant: []string{"allow ", "deny all;", "satisfy any;"},
+		},
+		{
+			name:    "nginx allowlist",
+			kind:    ServerNginx,
+			domain:  entities.Domain{IP: "10.0.0.5", Access: allowlist},
+			want:    []string{"    allow 5.6.7.8/32;\n    deny all;"},
+			notWant: []string{"10.8.0.0/16", "satisfy any;"},
+		},
+		{
+			name:   "nginx vpn only",
+			kind:   ServerNginx,
+			domain: entities.Domain{IP: "10.0.0.5", Access: vpnOnly},
+			want:   []string{"    allow 5.6.7.8/32;\n    allow 10.8.0.0/16;\n    deny all;"},
+		},
+		{
+			name:   "nginx office bypass",
+			kind:   ServerNginx,
+			domain: entities.Domain{IP: "10.0.0.5", BasicAuth: true, Access: bypass},
+			want:   []string{"auth_basic Restricted;", "satisfy any;\n    allow 1.2.3.0/24;\n    deny all;"},
+		},
+		{
+			name:    "nginx office bypass without basic auth",
+			kind:    ServerNginx,
+			domain:  entities.Domain{IP: "10.0.0.5", Access: bypass},
+			want:    []string{"auth_basic off;"},
+			notWant: []string{"satisfy any;", "1.2.3.0/24"},
+		},
+		{
+			name:    "caddy open",
+			kind:    ServerCaddy,
+			domain:  entities.Domain{IP: "10.0.0.5"},
+			notWant: []string{"remote_ip", "respond 403"},
+		},
+		{
+			name:   "caddy vpn only",
+			kind:   ServerCaddy,
+			domain: entities.Domain{IP: "10.0.0.5", Access: vpnOnly},
+			want:   []string{"@denied not remote_ip 5.6.7.8/32 10.8.0.0/16\n        handle @denied {\n                respond 403"},
+		},
+		{
+			name:    "caddy office bypass",
+			kind:    ServerCaddy,
+			domain:  entities.Domain{IP: "10.0.0.5", BasicAuth: true, Access: bypass},
+			want:    []string{"@auth not remote_ip 1.2.3.0/24\n        basicauth @auth {"},
+			notWant: []string{"@denied", "basicauth /*"},
+		},
+		{
+			name:    "caddy basic auth",
+			kind:    ServerCaddy,
+			domain:  entities.Domain{IP: "10.0.0.5", BasicAuth: true},
+			want:    []string{"basicauth /* {"},
+			notWant: []string{"@auth"},
+		},
+	})
+}
+
+func TestCreateProxy(t *testing.T) {
+	routes := []entities.Route{{Path: "/api", IP: "10.0.0.6", Port: "8080"}}
+	runRenderTests(t, Settings{}, []renderTest{
+		{
+			name:    "nginx defaults",
+			kind:    ServerNginx,
+			domain:  entities.Domain{IP: "10.0.0.5"},
+			want:    []string{"proxy_pass http://j-doe.domain.tld-upstream;", "proxy_read_timeout 180s;"},
+			notWant: []string{"Upgrade", "client_max_body_size", "grpc_"},
+		},
+		{
+			name:   "nginx websocket",
+			kind:   ServerNginx,
+			domain: entities.Domain{IP: "10.0.0.5", Routes: routes, Proxy: entities.Proxy{Websocket: true}},
+			want: []string{
+				"proxy_pass http://10.0.0.6:8080;",
+				"proxy_http_version 1.1;\n        proxy_set_header Upgrade $http_upgrade;\n        proxy_set_header Connection \"upgrade\";",
+			},
+		},
+		{
+			name:    "nginx grpc",
+			kind:    ServerNginx,
+			domain:  entities.Domain{IP: "10.0.0.5", Routes: routes, Proxy: entities.Proxy{Protocol: ProtocolGrpc}},
+			want:    []string{"grpc_pass grpc://10.0.0.6:8080;", "grpc_pass grpc://j-doe.domain.tld-upstream;", "grpc_set_header Host $host;", "grpc_read_timeout 180s;"},
+			notWant: []string{"proxy_", "Upgrade"},
+		},
+		{
+			name:   "nginx grpc over tls",
+			kind:   ServerNginx,
+			domain: entities.Domain{IP: "10.0.0.5", FullSsl: true, Proxy: entities.Proxy{Protocol: ProtocolGrpc}},
+			want:   []string{"server 10.0.0.5:443;", "grpc_pass grpcs://j-doe.domain.tld-upstream;"},
+		},
+		{
+			name:   "nginx timeout and max body",
+			kind:   ServerNginx,
+			domain: entities.Domain{IP: "10.0.0.5", Proxy: entities.Proxy{Timeout: "5m", MaxBody: "100m"}},
+			want:   []string{"client_max_body_size 100m;", "proxy_connect_timeout 120s;", "proxy_send_timeout 300s;", "proxy_read_timeout 300s;"},
+		},
+		{
+			name:    "caddy defaults",
+			kind:    ServerCaddy,
+			domain:  entities.Domain{IP: "10.0.0.5"},
+			want:    []string{"to http://10.0.0.5:80"},
+			notWant: []string{"transport http", "request_body"},
+		},
+		{
+			name:   "caddy h2c",
+			kind:   ServerCaddy,
+			domain: entities.Domain{IP: "10.0.0.5", Proxy: entities.Proxy{Protocol: ProtocolH2c}},
+			want:   []string{"transport http {\n                          versions h2c 2\n                        }"},
+		},
+		{
+			name:   "caddy grpc over tls",
+			kind:   ServerCaddy,
+			domain: entities.Domain{IP: "10.0.0.5", FullSsl: true, Proxy: entities.Proxy{Protocol: ProtocolGrpc}},
+			want:   []string{"to https://10.0.0.5:443", "tls_insecure_skip_verify\n                          versions 2"},
+		},
+		{
+			name:   "caddy timeout and max body",
+			kind:   ServerCaddy,
+			domain: entities.Domain{IP: "10.0.0.5", Proxy: entities.Proxy{Timeout: "5m", MaxBody: "100m"}},
+			want: []string{
+				"request_body {\n                max_size 100MB\n        }",
+				"dial_timeout 120s\n                          write_timeout 300s\n                          read_timeout 300s",
+			},
+		},
+	})
+}
+
+func TestCreateLogs(t *testing.T) {
+	settings := Settings{LogMaxSizeMB: 10, LogKeep: 3}
+	runRenderTests(t, settings, []renderTest{
+		{
+			name:    "nginx logs off",
+			kind:    ServerNginx,
+			domain:  entities.Domain{IP: "10.0.0.5"},
+			want:    []string{"access_log off;", "error_log  /dev/null;"},
+			notWant: []string{".access.log"},
+		},
+		{
+			name:   "nginx logs on",
+			kind:   ServerNginx,
+			domain: entities.Domain{IP: "10.0.0.5", Logs: true},
+			want:   []string{"/logs/j-doe.domain.tld.access.log;", "/logs/j-doe.domain.tld.error.log warn;"},
+		},
+		{
+			name:    "caddy logs off",
+			kind:    ServerCaddy,
+			domain:  entities.Domain{IP: "10.0.0.5"},
+			notWant: []string{"log {"},
+		},
+		{
+			name:   "caddy logs on",
+			kind:   ServerCaddy,
+			domain: entities.Domain{IP: "10.0.0.5", Logs: true},
+			want: []string{
+				"/logs/j-doe.domain.tld.access.log {\n                        roll_size 10MiB\n                        roll_keep 3\n                }",
+			},
+			notWant: []string{".error.log"},
+		},
+	})
+}
@@ -38,7 +38,7 @@ func main() {
 
 	slackBot := bot.New(cfg.Slack.AuthToken, cfg.Slack.AppToken, cfg.Slack.Channel, cfg.App.AdminEmails, handler, c)
 
-	stopCh := make(chan os.Signal)
+	stopCh := make(chan os.Signal, 1)
 	signal.Notify(stopCh, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-stopCh
@@ -1,5 +1,13 @@
 package pritunl
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
 func (c *Client) GetUserKeyZipUrl(email string) (url string, err error) {
 	org, err := c.GetOrganization()
 	if err != nil {
@@ -43,3 +51,44 @@ func (c *Client) DeleteUserFromDefaultOrg(email string) error {
 	}
 	return nil
 }
+
+// GetVirtualAddresses returns VPN addresses of connected users of the default organization by their emails.
+func (c *Client) GetVirtualAddresses() (addresses map[string]string, err error) {
+	org, err := c.GetOrganization()
+	if err != nil {
+		return
+	}
+	req, err := c.newRequest(http.MethodGet, endpointUser+"/"+org.ID, nil)
+	if err != nil {
+		return
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			return
+		}
+	}(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status when getting users: %s", resp.Status)
+	}
+	var users []UserServers
+	err = json.NewDecoder(resp.Body).Decode(&users)
+	if err != nil {
+		return
+	}
+	addresses = map[string]string{}
+	for _, u := range users {
+		for _, s := range u.Servers {
+			if s.Status && s.VirtAddress != "" {
+				// the address may come with the network mask
+				addresses[u.Email], _, _ = strings.Cut(s.VirtAddress, "/")
+				break
+			}
+		}
+	}
+	return addresses, nil
+}
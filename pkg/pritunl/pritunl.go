@@ -36,6 +36,19 @@ type User struct {
 	Disabled         bool   `json:"disabled"`
 }
 
+// UserServers is a user with the status of its VPN servers.
+type UserServers struct {
+	Email   string       `json:"email"`
+	Servers []UserServer `json:"servers"`
+}
+
+type UserServer struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Status      bool   `json:"status"`
+	VirtAddress string `json:"virt_address"`
+}
+
 type Key struct {
 	ID        string `json:"id"`
 	KeyURL    string `json:"key_url"`